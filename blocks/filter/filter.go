@@ -2,39 +2,157 @@ package filter
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/charmbracelet/bubbletea"
 	"github.com/gas/fancy-welcome/config"
+	"github.com/gas/fancy-welcome/logging"
 	"github.com/gas/fancy-welcome/shared/block"
+	"github.com/gas/fancy-welcome/shared/data"
 	"github.com/gas/fancy-welcome/themes"
 )
 
-// Este es un esqueleto básico.
+const (
+	defaultMaxLines  = 500 // Líneas que se guardan para la vista expandida.
+	defaultViewLines = 5   // Líneas que se muestran en el dashboard.
+)
+
+// FilterBlock filtra la salida del bloque al que escucha ('listens_to')
+// con una consulta al estilo grep (ver Options).
 type FilterBlock struct {
 	id        string
+	styles    *themes.StyleSheet
 	listensTo string
 	filter    string
+	position  string
+	maxLines  int
+	viewLines int
+
+	matcher *Matcher
+	err     error
+	lines   []string // Buffer acotado de líneas coincidentes (y su contexto).
+
+	chrome    block.Chrome
+	updatedAt time.Time // Cuándo llegó la última salida del bloque escuchado
 }
 
 func New() block.Block { return &FilterBlock{} }
 
-func (b *FilterBlock) Init(blockConfig map[string]interface{}, globalConfig config.GeneralConfig, theme *themes.Theme) error {
-	b.id = blockConfig["name"].(string)
+// ConfigSchema declara las claves de un bloque Filter.
+func (b *FilterBlock) ConfigSchema() block.Schema {
+	return block.Schema{Fields: []block.Field{
+		{Key: "listens_to", Type: block.TypeBlockRef, Required: true, Doc: "bloque cuya salida se filtra"},
+		{Key: "filter", Type: block.TypeString, Required: true, Doc: "consulta al estilo grep"},
+		{Key: "max_lines", Type: block.TypeInt, Doc: "líneas guardadas para la vista expandida"},
+		{Key: "view_lines", Type: block.TypeInt, Doc: "líneas mostradas en el dashboard"},
+	}}
+}
+
+func (b *FilterBlock) Init(blockConfig map[string]interface{}, globalConfig config.GeneralConfig, styles *themes.StyleSheet) error {
+	b.id, _ = blockConfig["name"].(string)
 	b.listensTo, _ = blockConfig["listens_to"].(string)
 	b.filter, _ = blockConfig["filter"].(string)
+
+	b.position, _ = blockConfig["position"].(string)
+	if b.position == "" {
+		b.position = "full-width"
+	}
+
+	b.maxLines = intOption(blockConfig, "max_lines", defaultMaxLines)
+	b.viewLines = intOption(blockConfig, "view_lines", defaultViewLines)
+
+	b.styles = styles
+	b.chrome = block.ChromeFrom(blockConfig)
+
+	// Un filtro mal escrito no impide crear el bloque: mostramos el error en su vista.
+	opts, err := ParseQuery(b.filter)
+	if err == nil {
+		b.matcher, err = NewMatcher(opts)
+	}
+	if err != nil {
+		logging.Log.Printf("[%s] Invalid filter %q: %v", b.id, b.filter, err)
+		b.err = err
+	}
 	return nil
 }
 
-// Por ahora, el Update no hace nada más que escuchar.
+// intOption lee un entero positivo de la config del bloque, con valor por defecto.
+func intOption(blockConfig map[string]interface{}, key string, def int) int {
+	switch v := blockConfig[key].(type) {
+	case int64:
+		if v > 0 {
+			return int(v)
+		}
+	case float64:
+		if v > 0 {
+			return int(v)
+		}
+	case int:
+		if v > 0 {
+			return v
+		}
+	}
+	return def
+}
+
 func (b *FilterBlock) Update(msg tea.Msg) (block.Block, tea.Cmd) {
-	// Aquí iría la lógica para procesar TeeOutputMsg
+	m, ok := msg.(block.TeeOutputMsg)
+	if !ok || m.SourceBlockID != b.listensTo || b.matcher == nil {
+		return b, nil
+	}
+
+	input := data.AsLines(m.Output)
+	b.updatedAt = time.Now()
+
+	// Una salida completa sustituye a la anterior; un lote de stream se acumula.
+	if !m.Streamed {
+		b.matcher.Reset()
+		b.lines = nil
+	}
+	b.lines = append(b.lines, b.matcher.Feed(input)...)
+	if len(b.lines) > b.maxLines {
+		b.lines = b.lines[len(b.lines)-b.maxLines:]
+	}
+
+	// Este bloque reacciona a otros, no necesita sus propios ticks.
 	return b, nil
 }
 
+func (b *FilterBlock) header() string {
+	if b.err != nil {
+		return fmt.Sprintf("Filtro '%s' sobre '%s': %v", b.filter, b.listensTo, b.err)
+	}
+	return fmt.Sprintf("Filtro '%s' sobre '%s': %d coincidencias", b.filter, b.listensTo, b.matcher.Matches())
+}
+
 func (b *FilterBlock) View() string {
-	return fmt.Sprintf("Filtro para '%s' buscando '%s'", b.listensTo, b.filter)
+	lines := b.lines
+	if len(lines) > b.viewLines {
+		lines = lines[len(lines)-b.viewLines:]
+	}
+	if len(lines) == 0 {
+		return b.styles.Base.Render(b.header())
+	}
+	return b.styles.Base.Render(b.header() + "\n" + strings.Join(lines, "\n"))
+}
+
+// ExpandedView muestra todas las líneas guardadas en el buffer.
+func (b *FilterBlock) ExpandedView() string {
+	return b.header() + "\n\n" + strings.Join(b.lines, "\n")
 }
 
 // Métodos para cumplir la interfaz
-func (b *FilterBlock) Name() string     { return b.id }
-func (b *FilterBlock) Position() string { return "full-width" } // O leer de config
-func (b *FilterBlock) RendererName() string { return "raw_text" }
\ No newline at end of file
+func (b *FilterBlock) Name() string               { return b.id }
+func (b *FilterBlock) Position() string           { return b.position }
+func (b *FilterBlock) RendererName() string       { return "raw_text" }
+func (b *FilterBlock) Styles() *themes.StyleSheet { return b.styles }
+
+// Status cumple block.Reporter. Un filtro no se refresca solo: se
+// actualiza cuando llega la salida del bloque al que escucha.
+func (b *FilterBlock) Status() block.Status {
+	return block.Status{Chrome: b.chrome, UpdatedAt: b.updatedAt, Err: b.err}
+}
+
+// SetStyles cambia los estilos sin perder las líneas acumuladas.
+func (b *FilterBlock) SetStyles(styles *themes.StyleSheet) { b.styles = styles }
@@ -0,0 +1,272 @@
+// blocks/filter/options.go
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Options son las opciones de un filtro, con la sintaxis de grep:
+//
+//	-i      ignora mayúsculas/minúsculas
+//	-v      invierte la búsqueda (líneas que NO coinciden)
+//	-F      el patrón es un texto fijo, no una expresión regular
+//	-E      el patrón es una expresión regular (por defecto)
+//	-w      solo coincidencias de palabra completa
+//	-A N    N líneas de contexto después de cada coincidencia
+//	-B N    N líneas de contexto antes de cada coincidencia
+//	-C N    N líneas de contexto antes y después
+//	-m N    se detiene tras N coincidencias
+//
+// Todo lo que no es un flag forma el patrón. '--' termina los flags.
+type Options struct {
+	IgnoreCase bool
+	Invert     bool
+	Fixed      bool
+	Word       bool
+	Before     int
+	After      int
+	MaxCount   int
+	Pattern    string
+}
+
+// ParseQuery convierte una consulta como `-i -C 2 "disk full"` en Options.
+func ParseQuery(query string) (Options, error) {
+	var opts Options
+
+	tokens, err := splitQuery(query)
+	if err != nil {
+		return opts, err
+	}
+
+	var patternParts []string
+	flagsDone := false
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if flagsDone || !strings.HasPrefix(tok, "-") || tok == "-" {
+			patternParts = append(patternParts, tok)
+			continue
+		}
+		if tok == "--" {
+			flagsDone = true
+			continue
+		}
+
+		// Flags agrupados (-iv) y numéricos pegados o separados (-C3, -C 3).
+		flags := tok[1:]
+		for j := 0; j < len(flags); j++ {
+			switch c := flags[j]; c {
+			case 'i':
+				opts.IgnoreCase = true
+			case 'v':
+				opts.Invert = true
+			case 'F':
+				opts.Fixed = true
+			case 'E':
+				opts.Fixed = false
+			case 'w':
+				opts.Word = true
+			case 'A', 'B', 'C', 'm':
+				arg := flags[j+1:]
+				if arg == "" {
+					if i+1 >= len(tokens) {
+						return opts, fmt.Errorf("el flag -%c necesita un número", c)
+					}
+					i++
+					arg = tokens[i]
+				}
+				n, err := strconv.Atoi(arg)
+				if err != nil || n < 0 {
+					return opts, fmt.Errorf("valor inválido para -%c: %q", c, arg)
+				}
+				switch c {
+				case 'A':
+					opts.After = n
+				case 'B':
+					opts.Before = n
+				case 'C':
+					opts.Before, opts.After = n, n
+				case 'm':
+					opts.MaxCount = n
+				}
+				j = len(flags) // El resto del token era el número.
+			default:
+				return opts, fmt.Errorf("flag desconocido: -%c", c)
+			}
+		}
+	}
+
+	opts.Pattern = strings.Join(patternParts, " ")
+	if opts.Pattern == "" {
+		return opts, fmt.Errorf("el filtro no tiene patrón")
+	}
+	return opts, nil
+}
+
+// splitQuery separa la consulta en palabras respetando comillas simples y dobles.
+func splitQuery(query string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	var quote rune
+	inToken := false
+
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t':
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("comillas sin cerrar en el filtro")
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// Matcher aplica unas Options a un flujo de líneas. Guarda estado entre
+// llamadas a Feed (contexto previo, contexto pendiente, coincidencias),
+// así que sirve igual para una salida completa que para lotes de un stream.
+type Matcher struct {
+	opts Options
+	re   *regexp.Regexp
+
+	before      []string // Últimas líneas no emitidas, para -B.
+	afterLeft   int      // Líneas de contexto posterior que faltan por emitir.
+	matches     int
+	lineNo      int
+	lastEmitted int
+}
+
+// NewMatcher compila el patrón de las opciones.
+func NewMatcher(opts Options) (*Matcher, error) {
+	pattern := opts.Pattern
+	if opts.Fixed {
+		pattern = regexp.QuoteMeta(pattern)
+	}
+	if opts.Word {
+		pattern = `\b(?:` + pattern + `)\b`
+	}
+	if opts.IgnoreCase {
+		pattern = `(?i)` + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("expresión regular inválida: %w", err)
+	}
+	return &Matcher{opts: opts, re: re}, nil
+}
+
+// Options devuelve las opciones con las que se creó el matcher.
+func (m *Matcher) Options() Options { return m.opts }
+
+// Matches devuelve cuántas coincidencias se han visto desde el último Reset.
+func (m *Matcher) Matches() int { return m.matches }
+
+// Match indica si una línea suelta cumple el filtro (sin contexto ni límite).
+func (m *Matcher) Match(line string) bool {
+	return m.re.MatchString(line) != m.opts.Invert
+}
+
+// Reset olvida el estado acumulado, para empezar con una salida nueva.
+func (m *Matcher) Reset() {
+	m.before = nil
+	m.afterLeft = 0
+	m.matches = 0
+	m.lineNo = 0
+	m.lastEmitted = 0
+}
+
+// Feed procesa un lote de líneas y devuelve las que hay que mostrar,
+// incluyendo el contexto y separadores "--" entre grupos, como grep.
+func (m *Matcher) Feed(lines []string) []string {
+	var out []string
+	hasContext := m.opts.Before > 0 || m.opts.After > 0
+
+	emit := func(line string) {
+		if hasContext && m.lastEmitted > 0 && m.lineNo > m.lastEmitted+1 {
+			out = append(out, "--")
+		}
+		out = append(out, line)
+		m.lastEmitted = m.lineNo
+	}
+
+	for _, line := range lines {
+		m.lineNo++
+		limitReached := m.opts.MaxCount > 0 && m.matches >= m.opts.MaxCount
+
+		if !limitReached && m.Match(line) {
+			m.matches++
+			// Volcamos el contexto previo con su numeración original.
+			current := m.lineNo
+			for k, prev := range m.before {
+				m.lineNo = current - len(m.before) + k
+				emit(prev)
+			}
+			m.lineNo = current
+			m.before = nil
+			emit(line)
+			m.afterLeft = m.opts.After
+			continue
+		}
+
+		if m.afterLeft > 0 {
+			m.afterLeft--
+			emit(line)
+			continue
+		}
+
+		if m.opts.Before > 0 && !limitReached {
+			m.before = append(m.before, line)
+			if len(m.before) > m.opts.Before {
+				m.before = m.before[1:]
+			}
+		}
+	}
+	return out
+}
+
+// Highlight marca con 'mark' los fragmentos de la línea que coinciden con
+// el patrón. Con -v no hay nada que resaltar y la línea se devuelve tal cual.
+func (m *Matcher) Highlight(line string, mark func(string) string) string {
+	if m.opts.Invert {
+		return line
+	}
+	locs := m.re.FindAllStringIndex(line, -1)
+	if len(locs) == 0 {
+		return line
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, loc := range locs {
+		if loc[0] == loc[1] {
+			continue // Coincidencias vacías (p. ej. '^'): nada que marcar.
+		}
+		builder.WriteString(line[last:loc[0]])
+		builder.WriteString(mark(line[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	builder.WriteString(line[last:])
+	return builder.String()
+}
@@ -4,12 +4,15 @@ package parsers
 import (
 	"bytes"
 	"os/exec"
+	"strconv"
 	"strings"
+
+	"github.com/gas/fancy-welcome/shared/data"
 )
 
 type AppCountParser struct{}
 
-func (p *AppCountParser) Parse(input string) (interface{}, error) {
+func (p *AppCountParser) Parse(input string) (data.Value, error) {
 	// This parser ignores the input and runs its own commands.
 	
 	// Helper function to run a command and count lines
@@ -25,7 +28,7 @@ func (p *AppCountParser) Parse(input string) (interface{}, error) {
 		// Subtract 1 to account for the header line in many list commands
 		count := len(strings.Split(strings.Trim(out.String(), "\n"), "\n"))
 		if count > 0 {
-			return string(rune(count-1))
+			return strconv.Itoa(count - 1)
 		}
 		return "0"
 	}
@@ -35,12 +38,14 @@ func (p *AppCountParser) Parse(input string) (interface{}, error) {
 	// You could add flatpak, etc. here
 	// flatpakCount := countLines("flatpak list")
 
-	data := [][]string{
+	table := data.Table{
 		{"Package Manager", "Count"},
 		{"snap", snapCount},
 		{"apt", aptCount},
 		// {"flatpak", flatpakCount},
 	}
 
-	return data, nil
-}
\ No newline at end of file
+	return table, nil
+}
+
+func (p *AppCountParser) ResultKind() data.Kind { return data.KindTable }
\ No newline at end of file
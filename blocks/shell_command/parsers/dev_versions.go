@@ -4,11 +4,13 @@ package parsers
 import (
 	"os/exec"
 	"strings"
+
+	"github.com/gas/fancy-welcome/shared/data"
 )
 
 type DevVersionsParser struct{}
 
-func (p *DevVersionsParser) Parse(input string) (interface{}, error) {
+func (p *DevVersionsParser) Parse(input string) (data.Value, error) {
 	// This parser also ignores input and runs its own set of commands.
 
 	// Helper to run a command and get its version output
@@ -30,12 +32,14 @@ func (p *DevVersionsParser) Parse(input string) (interface{}, error) {
 	pythonVersion := getVersion("python3", "--version")
 	goVersion := getVersion("go", "version")
 
-	data := [][]string{
+	table := data.Table{
 		{"Tool", "Version"},
 		{"Node.js", nodeVersion},
 		{"Python", pythonVersion},
 		{"Go", goVersion},
 	}
 
-	return data, nil
-}
\ No newline at end of file
+	return table, nil
+}
+
+func (p *DevVersionsParser) ResultKind() data.Kind { return data.KindTable }
\ No newline at end of file
@@ -5,13 +5,15 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/gas/fancy-welcome/shared/data"
 )
 
 type JournaldErrorsParser struct{}
 
-func (p *JournaldErrorsParser) Parse(input string) (interface{}, error) {
+func (p *JournaldErrorsParser) Parse(input string) (data.Value, error) {
 	lines := strings.Split(input, "\n")
-	var cleanedLines []string
+	var cleanedLines data.Lines
 	// Regex to capture the most common log format, might need adjustments
 	logPattern := regexp.MustCompile(`^\w{3}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2}\s+[\w.-]+\s+([^:]+):\s+(.*)`)
 
@@ -30,4 +32,6 @@ func (p *JournaldErrorsParser) Parse(input string) (interface{}, error) {
 		}
 	}
 	return cleanedLines, nil
-}
\ No newline at end of file
+}
+
+func (p *JournaldErrorsParser) ResultKind() data.Kind { return data.KindLines }
\ No newline at end of file
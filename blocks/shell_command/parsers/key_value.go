@@ -3,13 +3,16 @@ package parsers
 
 import (
 	"strings"
+
+	"github.com/gas/fancy-welcome/shared/data"
 )
 
 type KeyValueParser struct{}
 
 // Parse expects input in the format "key1=value1\nkey2=value2"
-func (p *KeyValueParser) Parse(input string) (interface{}, error) {
-	data := make(map[string]string)
+// Pairs keep the order in which they appear in the input.
+func (p *KeyValueParser) Parse(input string) (data.Value, error) {
+	var pairs data.KeyValue
 	lines := strings.Split(input, "\n")
 
 	for _, line := range lines {
@@ -19,8 +22,10 @@ func (p *KeyValueParser) Parse(input string) (interface{}, error) {
 		}
 		parts := strings.SplitN(trimmed, "=", 2)
 		if len(parts) == 2 {
-			data[parts[0]] = parts[1]
+			pairs = append(pairs, data.Pair{Key: parts[0], Value: parts[1]})
 		}
 	}
-	return data, nil
-}
\ No newline at end of file
+	return pairs, nil
+}
+
+func (p *KeyValueParser) ResultKind() data.Kind { return data.KindKeyValue }
\ No newline at end of file
@@ -0,0 +1,44 @@
+// blocks/shell_command/parsers/metrics.go
+package parsers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gas/fancy-welcome/shared/data"
+)
+
+type MetricsParser struct{}
+
+// Parse expects lines like "cpu=42.5" or "disk=81%". The numeric part becomes
+// the value and any trailing text its unit. Lines without '=' are skipped.
+func (p *MetricsParser) Parse(input string) (data.Value, error) {
+	var metrics data.Metrics
+	for _, line := range strings.Split(input, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		raw := strings.TrimSpace(parts[1])
+		end := len(raw)
+		for end > 0 {
+			if _, err := strconv.ParseFloat(raw[:end], 64); err == nil {
+				break
+			}
+			end--
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("metric %q has no numeric value: %q", parts[0], raw)
+		}
+		value, _ := strconv.ParseFloat(raw[:end], 64)
+		metrics = append(metrics, data.Metric{
+			Name:  strings.TrimSpace(parts[0]),
+			Value: value,
+			Unit:  strings.TrimSpace(raw[end:]),
+		})
+	}
+	return metrics, nil
+}
+
+func (p *MetricsParser) ResultKind() data.Kind { return data.KindMetrics }
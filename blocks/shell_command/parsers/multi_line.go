@@ -1,14 +1,18 @@
 // blocks/shell_command/parsers/multi_line.go
 package parsers
 
-import "strings"
+import (
+    "strings"
+
+    "github.com/gas/fancy-welcome/shared/data"
+)
 
 type MultiLineParser struct{}
 
-func (p *MultiLineParser) Parse(input string) (interface{}, error) {
+func (p *MultiLineParser) Parse(input string) (data.Value, error) {
     // Divide por nueva línea y elimina espacios en blanco de cada una.
     lines := strings.Split(input, "\n")
-    var cleanedLines []string
+    var cleanedLines data.Lines
     for _, line := range lines {
         trimmed := strings.TrimSpace(line)
         if trimmed != "" {
@@ -17,3 +21,5 @@ func (p *MultiLineParser) Parse(input string) (interface{}, error) {
     }
     return cleanedLines, nil
 }
+
+func (p *MultiLineParser) ResultKind() data.Kind { return data.KindLines }
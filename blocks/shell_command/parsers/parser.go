@@ -1,11 +1,15 @@
 // blocks/shell_command/parsers/parser.go
 package parsers
 
+import "github.com/gas/fancy-welcome/shared/data"
+
 // Parser es la interfaz que cada módulo de parseo debe implementar.
 // Toma un string de entrada y lo transforma en datos estructurados.
 type Parser interface {
     // Parse toma el texto crudo y devuelve los datos parseados o un error.
-    // Usamos interface{} para que cada parser pueda devolver el tipo de dato
-    // que más le convenga (string, []string, map[string]string, etc.).
-    Parse(input string) (interface{}, error)
+    // El resultado es uno de los tipos de shared/data (Text, Lines, Table...),
+    // que se guardan y se leen de la caché sin perder el tipo.
+    Parse(input string) (data.Value, error)
+    // ResultKind declara qué tipo de data.Value devuelve Parse.
+    ResultKind() data.Kind
 }
@@ -1,14 +1,18 @@
 // blocks/shell_command/parsers/raw_multi_line.go
 package parsers
 
-import "strings"
+import (
+    "strings"
+
+    "github.com/gas/fancy-welcome/shared/data"
+)
 
 type RawMultiLineParser struct{}
 
-func (p *RawMultiLineParser) Parse(input string) (interface{}, error) {
+func (p *RawMultiLineParser) Parse(input string) (data.Value, error) {
     // Divide por nueva línea y no elimina espacios en blanco de cada una.
     lines := strings.Split(input, "\n")
-    var cleanedLines []string
+    var cleanedLines data.Lines
     for _, line := range lines {
         if strings.TrimSpace(line) != "" {
             cleanedLines = append(cleanedLines, line)
@@ -16,3 +20,5 @@ func (p *RawMultiLineParser) Parse(input string) (interface{}, error) {
     }
     return cleanedLines, nil
 }
+
+func (p *RawMultiLineParser) ResultKind() data.Kind { return data.KindLines }
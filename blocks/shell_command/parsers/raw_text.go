@@ -1,10 +1,13 @@
 // blocks/shell_command/parsers/raw_text.go
 package parsers
 
+import "github.com/gas/fancy-welcome/shared/data"
 
 type RawTextParser struct{}
 
-func (p *RawTextParser) Parse(input string) (interface{}, error) {
+func (p *RawTextParser) Parse(input string) (data.Value, error) {
     // Simplemente devuelve la entrada tal cual.
-    return input, nil
-}
\ No newline at end of file
+    return data.Text(input), nil
+}
+
+func (p *RawTextParser) ResultKind() data.Kind { return data.KindText }
\ No newline at end of file
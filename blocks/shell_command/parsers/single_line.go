@@ -1,10 +1,16 @@
 // blocks/shell_command/parsers/single_line.go
 package parsers
 
-import "strings"
+import (
+    "strings"
+
+    "github.com/gas/fancy-welcome/shared/data"
+)
 
 type SingleLineParser struct{}
 
-func (p *SingleLineParser) Parse(input string) (interface{}, error) {
-    return strings.TrimSpace(input), nil
+func (p *SingleLineParser) Parse(input string) (data.Value, error) {
+    return data.Text(strings.TrimSpace(input)), nil
 }
+
+func (p *SingleLineParser) ResultKind() data.Kind { return data.KindText }
@@ -0,0 +1,41 @@
+// blocks/shell_command/parsers/tree.go
+package parsers
+
+import (
+	"strings"
+
+	"github.com/gas/fancy-welcome/shared/data"
+)
+
+type TreeParser struct{}
+
+// Parse builds a tree from indentation: each line is a child of the closest
+// previous line with less indentation. Tabs count as four spaces.
+func (p *TreeParser) Parse(input string) (data.Value, error) {
+	type frame struct {
+		indent int
+		node   *data.Node
+	}
+
+	root := &data.Node{}
+	stack := []frame{{indent: -1, node: root}}
+
+	for _, line := range strings.Split(input, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		expanded := strings.ReplaceAll(line, "\t", "    ")
+		label := strings.TrimLeft(expanded, " ")
+		indent := len(expanded) - len(label)
+
+		for len(stack) > 1 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].node
+		parent.Children = append(parent.Children, data.Node{Label: label})
+		stack = append(stack, frame{indent: indent, node: &parent.Children[len(parent.Children)-1]})
+	}
+	return data.Tree(root.Children), nil
+}
+
+func (p *TreeParser) ResultKind() data.Kind { return data.KindTree }
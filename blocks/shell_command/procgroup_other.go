@@ -0,0 +1,25 @@
+//go:build !unix
+
+// blocks/shell_command/procgroup_other.go
+package shell_command
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// Sin grupos de procesos, solo se puede matar al proceso principal.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func terminateGroup(cmd *exec.Cmd) error {
+	return killGroup(cmd)
+}
+
+func killGroup(cmd *exec.Cmd) error {
+	err := cmd.Process.Kill()
+	if errors.Is(err, os.ErrProcessDone) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,33 @@
+//go:build unix
+
+// blocks/shell_command/procgroup_unix.go
+package shell_command
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup lanza el comando en un grupo de procesos propio, para
+// poder matar también a sus hijos (sh -c "tail -f ... | grep ...").
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminateGroup pide al grupo del comando que termine (SIGTERM).
+func terminateGroup(cmd *exec.Cmd) error {
+	return signalGroup(cmd, syscall.SIGTERM)
+}
+
+// killGroup mata el grupo del comando (SIGKILL).
+func killGroup(cmd *exec.Cmd) error {
+	return signalGroup(cmd, syscall.SIGKILL)
+}
+
+func signalGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	err := syscall.Kill(-cmd.Process.Pid, sig)
+	if err == syscall.ESRCH {
+		return nil // Ya había terminado
+	}
+	return err
+}
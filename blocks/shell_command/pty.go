@@ -0,0 +1,89 @@
+// blocks/shell_command/pty.go
+package shell_command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/creack/pty"
+)
+
+const (
+	defaultPTYCols = 80 // Ancho si el layout aún no le ha dado uno al bloque
+	ptyRows        = 24 // Alto de la pseudo-terminal
+)
+
+// startPTY lanza 'cmd' en una pseudo-terminal de 'cols' columnas y devuelve
+// su lado maestro, del que se lee la salida. El proceso va en su propia
+// sesión (y grupo), así que killGroup también mata a sus hijos.
+func startPTY(cmd *exec.Cmd, cols int) (*os.File, error) {
+	size := ptySize(cols)
+	// Con una terminal delante, git y compañía abrirían un paginador que
+	// se quedaría esperando una tecla.
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("COLUMNS=%d", size.Cols), fmt.Sprintf("LINES=%d", size.Rows),
+		"PAGER=cat", "GIT_PAGER=cat")
+	return pty.StartWithSize(cmd, size)
+}
+
+// resizePTY cambia el ancho de una pseudo-terminal que ya está en marcha.
+func resizePTY(tty *os.File, cols int) error {
+	return pty.Setsize(tty, ptySize(cols))
+}
+
+func ptySize(cols int) *pty.Winsize {
+	if cols <= 0 {
+		cols = defaultPTYCols
+	}
+	return &pty.Winsize{Cols: uint16(cols), Rows: ptyRows}
+}
+
+// runPTY ejecuta un comando en una pseudo-terminal y devuelve su salida,
+// con los colores que saque al creer que escribe en una terminal.
+func runPTY(command string, cols int) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	tty, err := startPTY(cmd, cols)
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	var out bytes.Buffer
+	_, readErr := io.Copy(&out, tty)
+	err = cmd.Wait()
+	if err == nil && readErr != nil && !ptyClosed(readErr) {
+		err = readErr
+	}
+	return cleanTTYOutput(out.String()), err
+}
+
+// ptyClosed indica si un error al leer del maestro solo significa que ya
+// no queda nadie al otro lado (en Linux da EIO en lugar de EOF).
+func ptyClosed(err error) bool {
+	return errors.Is(err, syscall.EIO) || errors.Is(err, os.ErrClosed)
+}
+
+// cleanTTYOutput deja la salida de una terminal como texto: sin los \r de
+// los saltos de línea y, en cada línea, solo lo que se ve tras el último
+// retorno de carro (las barras de progreso se reescriben así).
+func cleanTTYOutput(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = cleanTTYLine(line)
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+func cleanTTYLine(line string) string {
+	line = strings.TrimRight(line, "\r")
+	if i := strings.LastIndexByte(line, '\r'); i >= 0 {
+		return line[i+1:]
+	}
+	return line
+}
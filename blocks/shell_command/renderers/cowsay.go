@@ -3,16 +3,17 @@ package renderers
 
 import (
     "fmt"
-    "github.com/charmbracelet/lipgloss"
+    "github.com/gas/fancy-welcome/shared/data"
+    "github.com/gas/fancy-welcome/themes"
     "github.com/gas/fancy-welcome/utils"
 )
 
 type CowsayRenderer struct{}
 
-func (r *CowsayRenderer) Render(data interface{}, width int, style lipgloss.Style) string {
-    message, ok := data.(string)
+func (r *CowsayRenderer) Render(value data.Value, width int, styles *themes.StyleSheet) string {
+    message, ok := value.(data.Text)
     if !ok {
-        return style.Render(fmt.Sprintf("Error: CowsayRenderer esperaba un string, recibió %T", data))
+        return styles.Error.Render(fmt.Sprintf("Error: CowsayRenderer esperaba texto, recibió %T", value))
     }
-    return style.Render(utils.Generate(message, width))
+    return styles.Base.Render(utils.Generate(string(message), width))
 }
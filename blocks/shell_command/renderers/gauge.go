@@ -6,59 +6,58 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/charmbracelet/lipgloss"
+	"github.com/gas/fancy-welcome/shared/data"
+	"github.com/gas/fancy-welcome/themes"
 )
 
 // renderGaugeHelper es una función interna para no duplicar código.
-func renderGauge(metrics map[string]string, style lipgloss.Style) string {
+// Con 'width' > 0 las barras se acortan para caber en ese ancho.
+func renderGauge(metrics data.Metrics, width int, styles *themes.StyleSheet) string {
 	var builder strings.Builder
 	barLength := 25
-
-	for key, valueStr := range metrics {
-		value, err := strconv.ParseFloat(valueStr, 64)
-		if err != nil {
-			continue
+	if width > 0 {
+		// Etiqueta, " [", "] " y el porcentaje ("100.0%").
+		labelWidth := 5
+		for _, metric := range metrics {
+			labelWidth = max(labelWidth, len(metric.Name))
 		}
+		barLength = min(barLength, max(width-labelWidth-10, 5))
+	}
 
+	for _, metric := range metrics {
+		value := metric.Value
 		if value < 0 { value = 0 }
 		if value > 100 { value = 100 }
 
 		filledCount := int((value / 100.0) * float64(barLength))
 		
-		filledStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("154"))
-		emptyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-		styledBar := filledStyle.Render(strings.Repeat("█", filledCount)) + emptyStyle.Render(strings.Repeat("░", barLength - filledCount))
+		styledBar := styles.GaugeFull.Render(strings.Repeat(styles.GaugeFullChar, filledCount)) +
+			styles.GaugeEmpty.Render(strings.Repeat(styles.GaugeEmptyChar, barLength - filledCount))
 
-		label := fmt.Sprintf("%-5s", strings.ToUpper(key))
+		label := fmt.Sprintf("%-5s", strings.ToUpper(metric.Name))
 		percent := fmt.Sprintf("%5.1f%%", value)
 
 		builder.WriteString(fmt.Sprintf("%s [%s] %s\n", label, styledBar, percent))
 	}
 
-	return style.Render(builder.String())
+	return styles.Base.Render(builder.String())
 }
 
 type GaugeRenderer struct{}
 
-func (r *GaugeRenderer) Render(data interface{}, width int, style lipgloss.Style) string {
-	// Intenta la conversión directa primero
-	if metrics, ok := data.(map[string]string); ok {
-		return renderGauge(metrics, style)
-	}
-
-	// ---- NUEVA LÓGICA PARA DATOS DE CACHÉ ----
-	// Si falla, intenta convertir desde el formato de la caché JSON
-	if interfaceMap, ok := data.(map[string]interface{}); ok {
-		metrics := make(map[string]string)
-		for key, valueInterface := range interfaceMap {
-			if value, ok := valueInterface.(string); ok {
-				metrics[key] = value
+func (r *GaugeRenderer) Render(value data.Value, width int, styles *themes.StyleSheet) string {
+	switch v := value.(type) {
+	case data.Metrics:
+		return renderGauge(v, width, styles)
+	case data.KeyValue:
+		// Los valores que no son números se ignoran, como siempre.
+		var metrics data.Metrics
+		for _, p := range v {
+			if f, err := strconv.ParseFloat(strings.TrimSuffix(p.Value, "%"), 64); err == nil {
+				metrics = append(metrics, data.Metric{Name: p.Key, Value: f})
 			}
 		}
-		// Una vez convertidos los datos, renderiza el gauge
-		return renderGauge(metrics, style)
+		return renderGauge(metrics, width, styles)
 	}
-	// ---- FIN DE LA NUEVA LÓGICA ----
-
-	return style.Render(fmt.Sprintf("Error: GaugeRenderer received incompatible data type %T", data))
-}
\ No newline at end of file
+	return styles.Error.Render(fmt.Sprintf("Error: GaugeRenderer received incompatible data type %T", value))
+}
@@ -3,17 +3,30 @@ package renderers
 
 import (
 	"fmt"
-	//"strings" // Asegúrate de que strings esté importado
-	"github.com/charmbracelet/lipgloss"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/gas/fancy-welcome/shared/data"
+	"github.com/gas/fancy-welcome/themes"
 )
 
 type PreformattedTextRenderer struct{}
 
-func (r *PreformattedTextRenderer) Render(data interface{}, width int, style lipgloss.Style) string {
-	if text, ok := data.(string); ok {
-		return text 
+func (r *PreformattedTextRenderer) Render(value data.Value, width int, styles *themes.StyleSheet) string {
+	var text string
+	switch v := value.(type) {
+	case data.Text:
+		text = string(v)
+	case data.Lines:
+		text = strings.Join(v, "\n")
+	default:
+		// Si recibimos un tipo de dato incompatible, podemos aplicar un estilo de error.
+		// Aquí sí podemos usar el estilo para el mensaje de error, ya que no es la salida del comando.
+		return styles.Error.Render(fmt.Sprintf("Error: PreformattedTextRenderer received incompatible data type %T", value))
+	}
+	// Los colores del comando no pasan por lipgloss: sin colores hay que quitarlos a mano.
+	if themes.Monochrome() {
+		return ansi.Strip(text)
 	}
-	// Si recibimos un tipo de dato incompatible, podemos aplicar un estilo de error.
-	// Aquí sí podemos usar el estilo para el mensaje de error, ya que no es la salida del comando.
-	return style.Render(fmt.Sprintf("Error: PreformattedTextRenderer received incompatible data type %T", data))
-}
\ No newline at end of file
+	return text
+}
@@ -4,33 +4,21 @@ package renderers
 import (
 	"fmt"
 	"strings"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/gas/fancy-welcome/shared/data"
+	"github.com/gas/fancy-welcome/themes"
 )
 
 type RawListRenderer struct{}
 
-func (r *RawListRenderer) Render(data interface{}, width int, style lipgloss.Style) string {
-	var builder strings.Builder
-
-	// Primero, intenta la conversión directa a []string
-	if lines, ok := data.([]string); ok {
-		for _, line := range lines {
-			builder.WriteString(fmt.Sprintf("%s\n", line))
-		}
-		return style.Render(builder.String())
-	}
-	
-	// Si falla, intenta la conversión a []interface{} (típico de la caché JSON)
-	if lines, ok := data.([]interface{}); ok {
-		for _, lineInterface := range lines {
-			// Convierte cada elemento de la interfaz a string
-			if line, ok := lineInterface.(string); ok {
-				builder.WriteString(fmt.Sprintf("%s\n", line))
-			}
-		}
-		return style.Render(builder.String())
+func (r *RawListRenderer) Render(value data.Value, width int, styles *themes.StyleSheet) string {
+	lines, ok := value.(data.Lines)
+	if !ok {
+		return styles.Error.Render(fmt.Sprintf("Error: RawListRenderer received incompatible data type %T", value))
 	}
 
-	// Si ninguna conversión funciona, muestra un error
-	return style.Render(fmt.Sprintf("Error: RawListRenderer received incompatible data type %T", data))
-}
\ No newline at end of file
+	var builder strings.Builder
+	for _, line := range lines {
+		builder.WriteString(fmt.Sprintf("%s\n", line))
+	}
+	return styles.Base.Render(builder.String())
+}
@@ -3,15 +3,19 @@ package renderers
 
 import (
     "fmt"
-    //"strings"
-    "github.com/charmbracelet/lipgloss"
+    "strings"
+    "github.com/gas/fancy-welcome/shared/data"
+    "github.com/gas/fancy-welcome/themes"
 )
 
 type RawTextRenderer struct{}
 
-func (r *RawTextRenderer) Render(data interface{}, width int, style lipgloss.Style) string {
-    if text, ok := data.(string); ok {
-        return style.Render(text)
+func (r *RawTextRenderer) Render(value data.Value, width int, styles *themes.StyleSheet) string {
+    switch v := value.(type) {
+    case data.Text:
+        return styles.Base.Render(string(v))
+    case data.Lines:
+        return styles.Base.Render(strings.Join(v, "\n"))
     }
-    return style.Render(fmt.Sprintf("Error: RawTextRenderer recibió datos incompatibles de tipo %T", data))
+    return styles.Error.Render(fmt.Sprintf("Error: RawTextRenderer recibió datos incompatibles de tipo %T", value))
 }
@@ -1,11 +1,17 @@
 // blocks/shell_command/renderers/renderer.go
 package renderers
 
-import "github.com/charmbracelet/lipgloss"
+import (
+    "github.com/gas/fancy-welcome/shared/data"
+    "github.com/gas/fancy-welcome/themes"
+)
 
 // Renderer es la interfaz que cada módulo de visualización debe implementar.
 // Toma datos estructurados y los convierte en un string para la TUI.
 type Renderer interface {
     // Render toma los datos parseados y devuelve el string final formateado.
-    Render(data interface{}, width int, style lipgloss.Style) string
+    // Los datos llegan siempre con su tipo concreto (data.Text, data.Table...),
+    // tanto si son recién obtenidos como si vienen de la caché.
+    // Los colores salen de 'styles', los estilos del tema del bloque.
+    Render(value data.Value, width int, styles *themes.StyleSheet) string
 }
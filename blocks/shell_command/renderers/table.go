@@ -5,11 +5,12 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/charmbracelet/lipgloss"
+	"github.com/gas/fancy-welcome/shared/data"
+	"github.com/gas/fancy-welcome/themes"
 )
 
 // renderTableHelper es una función interna para no duplicar código.
-func renderTable(tableData [][]string, style lipgloss.Style) string {
+func renderTable(tableData data.Table, styles *themes.StyleSheet) string {
 	if len(tableData) == 0 {
 		return ""
 	}
@@ -28,12 +29,11 @@ func renderTable(tableData [][]string, style lipgloss.Style) string {
 	}
 
 	var builder strings.Builder
-	headerStyle := style.Copy().Bold(true).Foreground(lipgloss.Color("12"))
 
 	// Render header
 	header := tableData[0]
 	for i, cell := range header {
-		builder.WriteString(headerStyle.Width(colWidths[i]).Render(cell))
+		builder.WriteString(styles.TableHeader.Width(colWidths[i]).Render(cell))
 		builder.WriteString("  ")
 	}
 	builder.WriteString("\n")
@@ -41,7 +41,7 @@ func renderTable(tableData [][]string, style lipgloss.Style) string {
 	// Render body
 	for _, row := range tableData[1:] {
 		for i, cell := range row {
-			builder.WriteString(style.Width(colWidths[i]).Render(cell))
+			builder.WriteString(styles.Base.Width(colWidths[i]).Render(cell))
 			builder.WriteString("  ")
 		}
 		builder.WriteString("\n")
@@ -52,33 +52,17 @@ func renderTable(tableData [][]string, style lipgloss.Style) string {
 
 type TableRenderer struct{}
 
-func (r *TableRenderer) Render(data interface{}, width int, style lipgloss.Style) string {
-	// Intenta la conversión directa primero
-	if tableData, ok := data.([][]string); ok {
-		return renderTable(tableData, style)
-	}
-
-	// ---- NUEVA LÓGICA PARA DATOS DE CACHÉ ----
-	// Si falla, intenta convertir desde el formato de la caché JSON
-	if interfaceSlice, ok := data.([]interface{}); ok {
-		var tableData [][]string
-		for _, rowInterface := range interfaceSlice {
-			if rowSlice, ok := rowInterface.([]interface{}); ok {
-				var row []string
-				for _, cellInterface := range rowSlice {
-					if cell, ok := cellInterface.(string); ok {
-						row = append(row, cell)
-					}
-				}
-				if len(row) > 0 {
-					tableData = append(tableData, row)
-				}
-			}
+func (r *TableRenderer) Render(value data.Value, width int, styles *themes.StyleSheet) string {
+	switch v := value.(type) {
+	case data.Table:
+		return renderTable(v, styles)
+	case data.KeyValue:
+		// Un key/value es una tabla de dos columnas sin cabecera propia.
+		table := data.Table{{"Key", "Value"}}
+		for _, p := range v {
+			table = append(table, []string{p.Key, p.Value})
 		}
-		// Una vez convertidos los datos, renderiza la tabla
-		return renderTable(tableData, style)
+		return renderTable(table, styles)
 	}
-	// ---- FIN DE LA NUEVA LÓGICA ----
-
-	return style.Render(fmt.Sprintf("Error: TableRenderer received incompatible data type %T", data))
-}
\ No newline at end of file
+	return styles.Error.Render(fmt.Sprintf("Error: TableRenderer received incompatible data type %T", value))
+}
@@ -0,0 +1,38 @@
+// blocks/shell_command/renderers/tree.go
+package renderers
+
+import (
+	"fmt"
+	"strings"
+	"github.com/gas/fancy-welcome/shared/data"
+	"github.com/gas/fancy-welcome/themes"
+)
+
+type TreeRenderer struct{}
+
+// renderNodes dibuja los nodos con guías al estilo de 'tree'.
+func renderNodes(builder *strings.Builder, nodes []data.Node, prefix string) {
+	for i, node := range nodes {
+		connector, childPrefix := "├── ", "│   "
+		if i == len(nodes)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+		builder.WriteString(prefix + connector + node.Label + "\n")
+		renderNodes(builder, node.Children, prefix+childPrefix)
+	}
+}
+
+func (r *TreeRenderer) Render(value data.Value, width int, styles *themes.StyleSheet) string {
+	tree, ok := value.(data.Tree)
+	if !ok {
+		return styles.Error.Render(fmt.Sprintf("Error: TreeRenderer received incompatible data type %T", value))
+	}
+
+	var builder strings.Builder
+	// Las raíces van sin guías; sus hijos, con ellas.
+	for _, root := range tree {
+		builder.WriteString(root.Label + "\n")
+		renderNodes(&builder, root.Children, "")
+	}
+	return styles.Base.Render(builder.String())
+}
@@ -0,0 +1,62 @@
+// blocks/shell_command/scrollback.go
+package shell_command
+
+const (
+	defaultScrollback = 1000 // Líneas de un stream que se guardan
+	defaultViewLines  = 10   // Las que se ven en el dashboard
+)
+
+// streamViewLines son las líneas de un stream que se ven en el dashboard:
+// 'view_lines' o, si no, las de por defecto; con 'max_height', como mucho
+// las que caben dentro del marco junto a la línea de estado.
+func streamViewLines(blockConfig map[string]interface{}) int {
+	lines := int(configNumber(blockConfig, "view_lines", defaultViewLines))
+	if maxHeight := int(configNumber(blockConfig, "max_height", 0)); maxHeight > 0 {
+		lines = min(lines, max(maxHeight-3, 1))
+	}
+	return lines
+}
+
+// lineRing guarda las últimas líneas de un stream: al llenarse, cada línea
+// nueva sustituye a la más antigua.
+type lineRing struct {
+	lines []string
+	start int // Posición de la más antigua, una vez lleno
+}
+
+func newLineRing(size int) *lineRing {
+	return &lineRing{lines: make([]string, 0, max(size, 1))}
+}
+
+// Push añade líneas al final.
+func (r *lineRing) Push(lines ...string) {
+	// De un lote más grande que el buffer solo quedarían las últimas.
+	if extra := len(lines) - cap(r.lines); extra > 0 {
+		lines = lines[extra:]
+	}
+	for _, line := range lines {
+		if len(r.lines) < cap(r.lines) {
+			r.lines = append(r.lines, line)
+			continue
+		}
+		r.lines[r.start] = line
+		r.start = (r.start + 1) % len(r.lines)
+	}
+}
+
+// Len es el número de líneas guardadas.
+func (r *lineRing) Len() int { return len(r.lines) }
+
+// Last devuelve las últimas 'n' líneas (todas si n <= 0), de la más
+// antigua a la más nueva.
+func (r *lineRing) Last(n int) []string {
+	total := len(r.lines)
+	if n <= 0 || n > total {
+		n = total
+	}
+	out := make([]string, 0, n)
+	for i := total - n; i < total; i++ {
+		out = append(out, r.lines[(r.start+i)%total])
+	}
+	return out
+}
@@ -7,20 +7,22 @@ import (
 	//"io"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"sort"
 	"strings"
 	"time"
-	"bufio"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gas/fancy-welcome/cache"
 	"github.com/gas/fancy-welcome/config"
 	"github.com/gas/fancy-welcome/blocks/shell_command/parsers"
 	"github.com/gas/fancy-welcome/blocks/shell_command/renderers"
 	"github.com/gas/fancy-welcome/themes"
 	"github.com/gas/fancy-welcome/logging" // paquete de logging
 	"github.com/gas/fancy-welcome/shared/block"
+	"github.com/gas/fancy-welcome/shared/data"
 )
 
 
@@ -28,7 +30,7 @@ import (
 // Mensaje para cuando los datos son nuevos (de un comando)
 type freshDataMsg struct {
 	blockID string
-	data    interface{}
+	data    data.Value
 	err     error
 }
 
@@ -36,17 +38,19 @@ func (m freshDataMsg) BlockID() string { return m.blockID } // <-- AÑADE ESTE M
 
 // Mensaje para cuando los datos vienen de la caché
 type cachedDataMsg struct {
-	blockID string
-	data    interface{}
-	err     error
+	blockID   string
+	data      data.Value
+	timestamp time.Time // Cuándo se obtuvieron los datos cacheados
+	err       error
 }
 
 func (m cachedDataMsg) BlockID() string { return m.blockID } // <-- AÑADE ESTE MÉTODO
 
-// Nuevo struct para guardar en el archivo de caché
+// Nuevo struct para guardar en el archivo de caché. ParsedData guarda
+// el tipo de los datos para reconstruirlos tal cual al leerlos.
 type cacheEntry struct {
-	Timestamp  time.Time   `json:"timestamp"`
-	ParsedData interface{} `json:"parsed_data"`
+	Timestamp  time.Time     `json:"timestamp"`
+	ParsedData data.Envelope `json:"parsed_data"`
 }
 
 
@@ -63,6 +67,8 @@ func init() {
 	registeredParsers["journald_errors"] = &parsers.JournaldErrorsParser{}
 	registeredParsers["key_value"] = &parsers.KeyValueParser{}
 	registeredParsers["raw_text"] = &parsers.RawTextParser{} 
+	registeredParsers["metrics"] = &parsers.MetricsParser{}
+	registeredParsers["tree"] = &parsers.TreeParser{}
 
 	// Register Renderers
 	registeredRenderers["raw_text"] = &renderers.RawTextRenderer{}
@@ -72,60 +78,94 @@ func init() {
 	registeredRenderers["list"] = &renderers.ListRenderer{}
 	registeredRenderers["raw_list"] = &renderers.RawListRenderer{}
 	registeredRenderers["preformatted_text"] = &renderers.PreformattedTextRenderer{}
+	registeredRenderers["tree"] = &renderers.TreeRenderer{}
+
+}
 
+// registeredNames devuelve las claves de un registro, ordenadas.
+func registeredNames[T any](registry map[string]T) []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ConfigSchema declara las claves de un bloque ShellCommand. Los parsers y
+// renderers válidos salen de los registros, así que un nombre mal escrito
+// se detecta al validar en lugar de dejar el bloque sin parser.
+func (b *ShellCommandBlock) ConfigSchema() block.Schema {
+	return block.Schema{Fields: []block.Field{
+		{Key: "command", Type: block.TypeString, Required: true, Doc: "comando a ejecutar con sh -c"},
+		{Key: "parser", Type: block.TypeString, Enum: registeredNames(registeredParsers), Default: "raw_text", Doc: "cómo se interpreta la salida"},
+		{Key: "renderer", Type: block.TypeString, Enum: registeredNames(registeredRenderers), Default: "raw_text", Doc: "cómo se muestran los datos"},
+		{Key: "update_seconds", Type: block.TypeNumber, Doc: "intervalo de refresco"},
+		{Key: "cache", Type: block.TypeNumber, Doc: "segundos que vale la caché en disco"},
+		{Key: "loading_indicator", Type: block.TypeString, Doc: "indicador de carga del tema"},
+		{Key: "streaming", Type: block.TypeBool, Doc: "lee la salida línea a línea"},
+		{Key: "pty", Type: block.TypeBool, Doc: "ejecuta el comando en una pseudo-terminal del ancho del bloque (colores, isatty)"},
+		{Key: "restart", Type: block.TypeString, Enum: []string{restartNever, restartOnFailure, restartAlways}, Doc: "cuándo se relanza un stream que termina"},
+		{Key: "max_restarts", Type: block.TypeInt, Doc: "reintentos seguidos de un stream; 0 = sin límite"},
+		{Key: "restart_backoff", Type: block.TypeNumber, Doc: "segundos antes del primer reintento; se doblan en cada uno"},
+		{Key: "batch_lines", Type: block.TypeInt, Doc: "líneas por lote de un stream, como mucho"},
+		{Key: "batch_ms", Type: block.TypeNumber, Doc: "milisegundos que espera una línea antes de enviarse"},
+		{Key: "scrollback", Type: block.TypeInt, Doc: "líneas de un stream que se guardan para la vista expandida"},
+		{Key: "view_lines", Type: block.TypeInt, Doc: "líneas de un stream que se ven en el dashboard"},
+	}}
 }
 
 // 1: Añadido el campo 'id' al struct del bloque.
 type ShellCommandBlock struct {
 	id           	string // ID único del bloque
-	style        	lipgloss.Style
+	styles       	*themes.StyleSheet
 	command      	string
 	parser       	parsers.Parser
 	renderer     	renderers.Renderer
-	parsedData   	interface{}
+	parsedData   	data.Value
 	currentError 	error
     cacheDuration 	time.Duration // 0 significa que la caché está desactivada
    	updateInterval 	time.Duration
-	//nextRunTime 	time.Time
+	dataTime       	time.Time // Cuándo se obtuvo parsedData (fresco o de la caché)
+	cacheChecked   	bool      // Ya se intentó servir la caché en el arranque
     isLoading 		bool
     spinner   		spinner.Model
+	indicator      	string // Indicador del tema que usa el spinner
+	chrome         	block.Chrome
     position     	string
     width 			int
 	rendererName   	string 
     isStreaming    	bool // <-- STREAM 
-    program      	*tea.Program // <-- ¡NUEVO CAMPO! Guardará el puntero.
+    program      	block.Sender // <-- ¡NUEVO CAMPO! Guardará el puntero.
+	streamOpts     	streamOptions // También decide el modo pty de las ejecuciones sueltas
+	stream         	*streamSupervisor // Stream en marcha; nil si no se ha lanzado o ya terminó
+	streamState    	streamStateMsg    // Último cambio de fase del stream
+	scrollback     	*lineRing         // Últimas líneas recibidas del stream
+	viewLines      	int               // Las que se ven en el dashboard
+	refreshKey     	key.Binding       // Tecla 'refresh' de [keys]
    	blockConfig    	map[string]interface{}
 }
 
 // Añadido 'blockID' al mensaje para saber a quién pertenece.
 type dataMsg struct {
 	blockID string
-	data    interface{}
-	err     error
-}
-
-// STREAM
-// streamLineMsg transporta una sola línea de un comando en modo streaming.
-type streamLineMsg struct {
-	blockID string
-	line    string
-}
-// Hacemos que sea un mensaje dirigido
-func (m streamLineMsg) BlockID() string { return m.blockID }
-
-
-// streamClosedMsg notifica que un comando en modo streaming ha terminado o ha fallado.
-type streamClosedMsg struct {
-	blockID string
+	data    data.Value
 	err     error
 }
-func (m streamClosedMsg) BlockID() string { return m.blockID }
 
 // SetProgram guarda la referencia al programa para uso en el streaming.
-func (b *ShellCommandBlock) SetProgram(p *tea.Program) {
+func (b *ShellCommandBlock) SetProgram(p block.Sender) {
     b.program = p
 }
 
+// Close para el stream, si hay, y mata su grupo de procesos.
+func (b *ShellCommandBlock) Close() error {
+	if b.stream == nil {
+		return nil
+	}
+	return b.stream.Stop()
+}
+
 // OTROS
 
 
@@ -134,11 +174,22 @@ func (b *ShellCommandBlock) Position() string {
 }
 
 func New() block.Block {
-	return &ShellCommandBlock{}
+	return &ShellCommandBlock{
+		refreshKey: key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "actualizar bloque")),
+	}
+}
+
+// SetKeys cumple block.KeyBinder.
+func (b *ShellCommandBlock) SetKeys(keys block.Keys) {
+	b.refreshKey = keys.Refresh
 }
 
 func (b *ShellCommandBlock) SetWidth(width int) {
 	b.width = width
+	// La pseudo-terminal de un stream en marcha se ajusta al bloque.
+	if b.stream != nil {
+		b.stream.SetWidth(width)
+	}
 }
 
 func (b *ShellCommandBlock) Name() string {
@@ -146,23 +197,93 @@ func (b *ShellCommandBlock) Name() string {
 	return b.id
 }
 
-// Helper para obtener la ruta del archivo de caché (duplicado de shell_command para uso en main)
-func (b *ShellCommandBlock) getCacheFilePath() string {
-    homeDir, _ := os.UserHomeDir()
-    return filepath.Join(homeDir, ".cache", "fancy-welcome", fmt.Sprintf("%s.json", b.id))
+// loadCacheCmd lee la caché del bloque y la devuelve como cachedDataMsg.
+func (b *ShellCommandBlock) loadCacheCmd() tea.Cmd {
+	return func() tea.Msg {
+		var entry cacheEntry
+		if err := cache.Load(b.id, &entry); err != nil {
+			return cachedDataMsg{blockID: b.id, err: err}
+		}
+		// Si se ha cambiado el parser, lo guardado es de otro tipo y el
+		// renderer no sabría qué hacer con ello: cuenta como si no hubiera.
+		if kind := b.parser.ResultKind(); entry.ParsedData.Kind != kind {
+			return cachedDataMsg{blockID: b.id, err: fmt.Errorf("la caché es de tipo %q y el parser da %q", entry.ParsedData.Kind, kind)}
+		}
+		value, err := entry.ParsedData.Unwrap()
+		if err != nil {
+			return cachedDataMsg{blockID: b.id, err: err}
+		}
+		return cachedDataMsg{blockID: b.id, data: value, timestamp: entry.Timestamp}
+	}
+}
+
+// saveCacheCmd escribe los datos en la caché sin bloquear el bucle de la UI.
+func (b *ShellCommandBlock) saveCacheCmd(value data.Value, timestamp time.Time) tea.Cmd {
+	id := b.id
+	return func() tea.Msg {
+		envelope, err := data.Wrap(value)
+		if err == nil {
+			err = cache.Save(id, cacheEntry{Timestamp: timestamp, ParsedData: envelope})
+		}
+		if err != nil {
+			logging.Log.Printf("[%s] Error writing cache: %v", id, err)
+			return block.NewNotification(block.SeverityWarning, id, "no se pudo guardar la caché: %v", err)
+		}
+		return nil
+	}
+}
+
+// isStale indica si los datos mostrados son más antiguos que la caché permitida.
+func (b *ShellCommandBlock) isStale() bool {
+	return b.cacheDuration > 0 && !b.dataTime.IsZero() && time.Since(b.dataTime) > b.cacheDuration
+}
+
+// Status cumple block.Reporter.
+func (b *ShellCommandBlock) Status() block.Status {
+	status := block.Status{
+		Chrome:    b.chrome,
+		UpdatedAt: b.dataTime,
+		Loading:   b.isLoading,
+		Err:       b.currentError,
+	}
+	switch {
+	case b.isLoading:
+		status.Spinner = b.spinner.View()
+	case b.isStreaming:
+		// Un stream no tiene ticks; solo se sabe cuándo se relanza.
+		if b.streamState.state == streamWaiting {
+			status.NextRefresh = b.streamState.retryAt
+		}
+	default:
+		status.NextRefresh = block.NextTick(b.id)
+	}
+	return status
+}
+
+// HandleKey cumple block.Interactive: con el foco, la tecla 'refresh'
+// vuelve a ejecutar el comando sin esperar al siguiente tick, o relanza un
+// stream que ya ha terminado.
+func (b *ShellCommandBlock) HandleKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	if !key.Matches(msg, b.refreshKey) || b.stream != nil {
+		return false, nil
+	}
+	if b.isLoading {
+		return true, nil // Ya se está actualizando
+	}
+	tick := block.NewBlockTickMsg(b.id)
+	return true, func() tea.Msg { return tick }
 }
 
 func (b *ShellCommandBlock) Spinner() *spinner.Model { return &b.spinner }
 
 func (b *ShellCommandBlock) SpinnerCmd() tea.Cmd { return b.spinner.Tick }
 
-func (b *ShellCommandBlock) Init(blockConfig map[string]interface{}, globalConfig config.GeneralConfig, theme *themes.Theme) error {
+func (b *ShellCommandBlock) Init(blockConfig map[string]interface{}, globalConfig config.GeneralConfig, styles *themes.StyleSheet) error {
 	b.blockConfig = blockConfig
 	b.id, _ = blockConfig["name"].(string)
 	b.position, _ = blockConfig["position"].(string)
-	b.style = lipgloss.NewStyle().
-		Background(lipgloss.Color(theme.Colors.Background)).
-		Foreground(lipgloss.Color(theme.Colors.Text))
+	b.styles = styles
+	b.chrome = block.ChromeFrom(blockConfig)
 	logging.Log.Printf("[%s] Initializing block...", b.id)
 
 	// --- LÓGICA DE DEPURACIÓN DEL INTERVALO ---
@@ -200,8 +321,16 @@ func (b *ShellCommandBlock) Init(blockConfig map[string]interface{}, globalConfi
 	// --- FIN DE LA LÓGICA DE DEPURACIÓN ---
 
 	b.command, _ = blockConfig["command"].(string)
-	if cacheSecs, ok := blockConfig["cache"].(float64); ok && cacheSecs > 0 {
-		b.cacheDuration = time.Duration(cacheSecs) * time.Second
+	// TOML decodifica 'cache = 60' como int64 y 'cache = 1.5' como float64.
+	var cacheSecs float64
+	switch v := blockConfig["cache"].(type) {
+	case float64:
+		cacheSecs = v
+	case int64:
+		cacheSecs = float64(v)
+	}
+	if cacheSecs > 0 {
+		b.cacheDuration = time.Duration(cacheSecs * float64(time.Second))
 	} else {
 		b.cacheDuration = 0
 	}
@@ -212,24 +341,47 @@ func (b *ShellCommandBlock) Init(blockConfig map[string]interface{}, globalConfi
 	rendererName, _ := blockConfig["renderer"].(string)
 	b.renderer = registeredRenderers[rendererName]
     b.rendererName = rendererName // para pasarselo a main
+	if b.parser == nil {
+		return fmt.Errorf("parser desconocido: %q", parserName)
+	}
+	if b.renderer == nil {
+		return fmt.Errorf("renderer desconocido: %q", rendererName)
+	}
 
 	indicatorStyle, _ := blockConfig["loading_indicator"].(string)
 	if indicatorStyle == "" {
 		indicatorStyle = "spinner"
 	}
+	b.indicator = indicatorStyle
 	spinnerOptions := []spinner.Option{
-		spinner.WithStyle(lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Colors.Primary))),
+		spinner.WithStyle(b.styles.Primary),
 	}
-	if style, ok := theme.Indicators[indicatorStyle]; ok && len(style.Frames) > 0 {
+	if style, ok := styles.Indicators[indicatorStyle]; ok && len(style.Frames) > 0 {
 		spinnerAnimation := spinner.Spinner{Frames: style.Frames, FPS: time.Second / 10}
 		spinnerOptions = append(spinnerOptions, spinner.WithSpinner(spinnerAnimation))
 	}
 	b.spinner = spinner.New(spinnerOptions...)
 
     b.isStreaming, _ = blockConfig["streaming"].(bool) // <-- STREAM
+	b.streamOpts = streamOptionsFrom(blockConfig)
+	b.scrollback = newLineRing(int(configNumber(blockConfig, "scrollback", defaultScrollback)))
+	b.viewLines = streamViewLines(blockConfig)
 	return nil
 }
 
+func (b *ShellCommandBlock) Styles() *themes.StyleSheet {
+	return b.styles
+}
+
+// SetStyles cambia los estilos (y el spinner) sin perder datos ni el stream.
+func (b *ShellCommandBlock) SetStyles(styles *themes.StyleSheet) {
+	b.styles = styles
+	b.spinner.Style = styles.Primary
+	if style, ok := styles.Indicators[b.indicator]; ok && len(style.Frames) > 0 {
+		b.spinner.Spinner = spinner.Spinner{Frames: style.Frames, FPS: time.Second / 10}
+	}
+}
+
 func (b *ShellCommandBlock) RendererName() string {
     return b.rendererName 
 }
@@ -256,13 +408,27 @@ func (b *ShellCommandBlock) Update(msg tea.Msg) (block.Block, tea.Cmd) {
 		// Si llegamos aquí, es nuestro turno de actualizar.
 		if b.isLoading { return b, nil }
 
+		// En el primer arranque, antes de ejecutar nada, servimos la caché.
+		if !b.cacheChecked && b.cacheDuration > 0 && !b.isStreaming {
+			b.cacheChecked = true
+			return b, b.loadCacheCmd()
+		}
+
         // --- LÓGICA DE DECISIÓN: ¿STREAMING O COMANDO NORMAL? ---
         if b.isStreaming {
+			// El stream se lanza una vez; si muere, lo relanza su supervisor.
+			if b.stream != nil {
+				return b, nil
+			}
+			if b.program == nil {
+				b.currentError = fmt.Errorf("no hay programa al que enviar el stream")
+				return b, nil
+			}
             logging.Log.Printf("[%s] Starting stream...", b.id)
             b.isLoading = true // Mostramos el spinner mientras se conecta
-            cmd := exec.Command("sh", "-c", b.command)
-            // Devolvemos un nuevo tipo de comando que escucha el stream
-			return b, listenToStream(b.program, cmd, b.id)
+			b.stream = newStreamSupervisor(b.id, b.command, b.program, b.streamOpts, b.width)
+			b.stream.Start()
+			return b, b.spinner.Tick
         } else {
 			// COMANDO NORMAL
 			b.isLoading = true
@@ -275,14 +441,30 @@ func (b *ShellCommandBlock) Update(msg tea.Msg) (block.Block, tea.Cmd) {
 
     // --- GESTIÓN DE MENSAJES DE STREAM ---
 
-    case streamClosedMsg:
+    // El supervisor cuenta cada cambio de fase: el error con el que murió
+    // el proceso se ve en el bloque hasta que vuelve a arrancar.
+    case streamStateMsg:
         if m.blockID != b.id { return b, nil }
-        b.isLoading = false
-        b.currentError = m.err
-        logging.Log.Printf("[%s] Closed stream...", b.id)
-
-        // El stream ha muerto, programamos un reintento.
-        return b, block.ScheduleNextTick(b.id, time.Second*5) // Reintentar en 5s
+        b.streamState = m
+        switch m.state {
+        case streamStarting:
+            if !b.isLoading {
+                b.isLoading = true
+                return b, b.spinner.Tick
+            }
+        case streamRunning:
+            b.isLoading = false
+            b.currentError = nil
+        case streamWaiting:
+            b.isLoading = false
+            b.currentError = m.err
+        case streamStopped:
+            logging.Log.Printf("[%s] Closed stream...", b.id)
+            b.isLoading = false
+            b.currentError = m.err
+            b.stream = nil // 'r' lo vuelve a lanzar
+        }
+        return b, nil
 
     // --- MENSAJES DE COMANDOS NORMALES ---
 
@@ -290,31 +472,73 @@ func (b *ShellCommandBlock) Update(msg tea.Msg) (block.Block, tea.Cmd) {
 	case freshDataMsg: // O infoMsg para system_info
 		if m.BlockID() != b.id { return b, nil }
 		b.isLoading = false
-		b.parsedData = m.data // o b.info = m.info
 		b.currentError = m.err
 
-		// Creamos el comando para emitir los datos
+		// Si el comando falla, conservamos los datos anteriores (se marcarán
+		// como caducados) en lugar de dejar el bloque vacío.
+		if m.err != nil {
+			return b, block.ScheduleNextTick(b.id, b.updateInterval)
+		}
+		b.parsedData = m.data // o b.info = m.info
+		b.dataTime = time.Now()
+
+		// Creamos el comando para emitir los datos. Corre en otra goroutine,
+		// así que se lleva una copia y no lee b.parsedData.
+		freshData := b.parsedData
 		teeCmd := func() tea.Msg {
 			return block.TeeOutputMsg{
 				SourceBlockID: b.id,
-				Output:        b.parsedData,
+				Output:        freshData,
 			}
 		}
 		
-		// Devolvemos AMBOS comandos: el del siguiente tick y el de la emisión "tee"
-		return b, tea.Batch(
+		// Devolvemos el siguiente tick, la emisión "tee" y, si hay caché, su escritura.
+		cmds := []tea.Cmd{
 			block.ScheduleNextTick(b.id, b.updateInterval),
 			teeCmd,
-		)
+		}
+		if b.cacheDuration > 0 {
+			cmds = append(cmds, b.saveCacheCmd(b.parsedData, b.dataTime))
+		}
+		return b, tea.Batch(cmds...)
 
-	//Es necesario hacer broadcast de la caché? En principio no.
+	// La caché también se difunde: ahora llega con su tipo concreto y los
+	// bloques que escuchan (filtros, contadores) la tratan igual que la fresca.
 	case cachedDataMsg:
 		if m.BlockID() != b.id { return b, nil }
-		b.isLoading = false
+
+		// Sin caché utilizable: carga normal.
+		if m.err != nil {
+			if !os.IsNotExist(m.err) {
+				logging.Log.Printf("[%s] Ignoring cache: %v", b.id, m.err)
+			}
+			b.isLoading = true
+			return b, tea.Batch(b.fetchDataCmd(), b.spinner.Tick)
+		}
+
 		b.parsedData = m.data
-		
-		// También usamos el nuevo planificador.
-		return b, block.ScheduleNextTick(b.id, b.updateInterval)
+		b.dataTime = m.timestamp
+		logging.Log.Printf("[%s] Serving cached data from %v", b.id, m.timestamp)
+
+		cachedData := b.parsedData
+		teeCmd := func() tea.Msg {
+			return block.TeeOutputMsg{SourceBlockID: b.id, Output: cachedData}
+		}
+
+		// Si la caché sigue fresca, no ejecutamos nada hasta que caduque o
+		// toque actualizar, lo que llegue antes.
+		if age := time.Since(m.timestamp); age < b.cacheDuration {
+			return b, tea.Batch(teeCmd, block.ScheduleNextTick(b.id, min(b.updateInterval, b.cacheDuration-age)))
+		}
+
+		// Caducada: la mostramos (marcada) mientras llegan datos nuevos.
+		b.isLoading = true
+		if block.OneShot() {
+			// En un volcado solo cuenta la salida nueva; si también se
+			// reenviara la vieja, los filtros la verían dos veces.
+			return b, b.fetchDataCmd()
+		}
+		return b, tea.Batch(teeCmd, b.fetchDataCmd(), b.spinner.Tick)
 
 	case block.StreamLineBatchMsg:
 			if m.BlockID() != b.id {
@@ -324,16 +548,18 @@ func (b *ShellCommandBlock) Update(msg tea.Msg) (block.Block, tea.Cmd) {
 			// Una vez que recibimos el primer lote, consideramos que ya no está "cargando".
 			b.isLoading = false
 
-			// Para la propia vista del bloque tail_log, podemos mostrar la última línea recibida.
-			if len(m.Lines) > 0 {
-				b.parsedData = m.Lines[len(m.Lines)-1]
-			}
+			// Las líneas van al scrollback, y las que se ven en el dashboard
+			// pasan por el parser como la salida de un comando normal.
+			b.scrollback.Push(m.Lines...)
+			b.dataTime = time.Now()
+			b.parsedData, b.currentError = b.parseLines(b.scrollback.Last(b.viewLines))
 			
 			// Creamos UN SOLO TeeOutputMsg que contiene TODAS las líneas.
 			teeCmd := func() tea.Msg {
 				return block.TeeOutputMsg{
 					SourceBlockID: b.id,
-					Output:        m.Lines, // <-- El Output ahora es un []string
+					Output:        data.Lines(m.Lines), // <-- El Output ahora es un lote de líneas
+					Streamed:      true,
 				}
 			}
 			
@@ -386,13 +612,22 @@ func (b *ShellCommandBlock) OLD_fetchDataCmd() tea.Cmd {
 
 // fetchDataCmd si no necesita p*program
 func (b *ShellCommandBlock) fetchDataCmd() tea.Cmd {
+	width := b.width // El layout puede cambiarlo mientras corre el comando
 	return func() tea.Msg {
 		// Lanzamos el trabajo pesado en una goroutine.
 
 		var output []byte
 		var err error
 
-		if b.command != "" {
+		if b.command != "" && b.streamOpts.pty {
+			// En una pseudo-terminal del ancho del bloque, como si se viera en una.
+			var text string
+			text, err = runPTY(b.command, width)
+			if err != nil {
+				return freshDataMsg{blockID: b.id, err: fmt.Errorf("falló la ejecución: %w", err)}
+			}
+			output = []byte(text)
+		} else if b.command != "" {
 			cmd := exec.Command("sh", "-c", b.command)
 			// CombinedOutput sigue siendo bloqueante, pero ahora dentro de la goroutine.
 			output, err = cmd.CombinedOutput()
@@ -453,75 +688,97 @@ func (b *ShellCommandBlock) FORMER_fetchDataCmd(p *tea.Program) tea.Cmd {
 func (b *ShellCommandBlock) View() string {
 	var content string
 
-	if b.currentError != nil {
-		errorMsg := fmt.Sprintf("Error en '%s': %v", b.id, b.currentError)
-		content = b.style.Copy().Foreground(lipgloss.Color("9")).Render(errorMsg)
-	} else if b.parsedData != nil {
+	if b.parsedData != nil {
 		// Si tenemos datos (antiguos o nuevos), los renderizamos.
-		content = b.renderer.Render(b.parsedData, b.width, b.style)
-	} else {
+		content = b.renderer.Render(b.parsedData, b.width, b.styles)
+		if b.isStale() {
+			marker := fmt.Sprintf("stale since %s", formatDataTime(b.dataTime))
+			content = lipgloss.JoinVertical(lipgloss.Left, content, b.styles.Muted.Render(marker))
+		}
+	} else if b.currentError == nil {
 		// No hay datos ni error, probablemente la carga inicial.
 		content = "..."
 	}
 
-	// Si está cargando, añadimos el spinner al final del contenido.
-	if b.isLoading {
+	if b.isStreaming {
+		if state := b.streamStatus(); state != "" {
+			content = lipgloss.JoinVertical(lipgloss.Left, content, state)
+		}
+	}
+
+	if b.currentError != nil {
+		errorMsg := b.styles.Error.Render(fmt.Sprintf("Error en '%s': %v", b.id, b.currentError))
+		if content == "" {
+			content = errorMsg
+		} else {
+			content = lipgloss.JoinVertical(lipgloss.Left, content, errorMsg)
+		}
+	}
+
+	// Si está cargando, añadimos el spinner al final del contenido
+	// (salvo que ya salga en el pie).
+	if b.isLoading && !b.chrome.Footer {
 		return lipgloss.JoinHorizontal(lipgloss.Top, content, " "+b.spinner.View())
 	}
 	return content
 }
 
-// listenToStream crea un comando que inicia un proceso y escucha su salida
-// línea por línea en una goroutine.
-// blocks/shell_command/shell_command.go
+// parseLines pasa unas líneas del stream por el parser del bloque.
+func (b *ShellCommandBlock) parseLines(lines []string) (data.Value, error) {
+	parsed, err := b.parser.Parse(strings.Join(lines, "\n"))
+	if err != nil {
+		return b.parsedData, fmt.Errorf("falló el parseo: %w", err)
+	}
+	return parsed, nil
+}
 
-func listenToStream(p *tea.Program, cmd *exec.Cmd, blockID string) tea.Cmd {
-	return func() tea.Msg {
-		stdout, err := cmd.StdoutPipe()
-		if err != nil { return streamClosedMsg{blockID: blockID, err: err} }
+// ExpandedView cumple block.Expander: en un stream, todo el scrollback
+// (con el parser y el renderer del bloque); si no, lo mismo que View.
+func (b *ShellCommandBlock) ExpandedView() string {
+	if !b.isStreaming || b.scrollback.Len() == 0 {
+		return b.View()
+	}
+	parsed, err := b.parseLines(b.scrollback.Last(0))
+	if err != nil || parsed == nil {
+		return strings.Join(b.scrollback.Last(0), "\n")
+	}
+	return b.renderer.Render(parsed, b.width, b.styles)
+}
 
-		if err := cmd.Start(); err != nil {
-			return streamClosedMsg{blockID: blockID, err: err}
-		}
+// Follows cumple block.Follower: la vista expandida de un stream sigue
+// sus líneas nuevas.
+func (b *ShellCommandBlock) Follows() bool {
+	return b.isStreaming
+}
 
-		scanner := bufio.NewScanner(stdout)
-		
-		go func() {
-			// Buffer para agrupar líneas
-			var lines []string
-			// Temporizador para enviar lotes cada 100ms
-			ticker := time.NewTicker(10 * time.Millisecond)
-			defer ticker.Stop()
-
-			for {
-				select {
-				// Caso 1: Ha pasado el tiempo del ticker
-				case <-ticker.C:
-					if len(lines) > 0 {
-						// Si tenemos líneas acumuladas, las enviamos
-						// p.Send(block.NewStreamLineBatchMsg{blockID: blockID, lines: lines})
-						p.Send(block.NewStreamLineBatchMsg(blockID, lines))
-						// Y vaciamos el buffer
-						lines = nil
-					}
-				// Caso 2: Leemos una nueva línea del scanner
-				default:
-					if !scanner.Scan() {
-						// Si el scanner falla o termina, el proceso ha muerto.
-						// Enviamos las últimas líneas que quedaran y cerramos.
-						if len(lines) > 0 {
-							// p.Send(block.StreamLineBatchMsg{blockID: blockID, lines: lines})
-							p.Send(block.NewStreamLineBatchMsg(blockID, lines))
-						}
-						p.Send(block.NewStreamClosedMsg(blockID, cmd.Wait()))
-						return
-					}
-					// Acumulamos la línea leída en el buffer
-					lines = append(lines, scanner.Text())
-				}
-			}
-		}()
+// streamStatus es la línea que cuenta en qué fase está el stream (vacía
+// mientras arranca: ya se ve el spinner).
+func (b *ShellCommandBlock) streamStatus() string {
+	state := b.streamState
+	switch state.state {
+	case streamRunning:
+		return b.styles.Success.Render("● en directo")
+	case streamWaiting:
+		attempt := fmt.Sprintf("%d", state.attempt)
+		if b.streamOpts.maxRestarts > 0 {
+			attempt += fmt.Sprintf("/%d", b.streamOpts.maxRestarts)
+		}
+		wait := max(time.Until(state.retryAt), 0).Round(time.Second)
+		return b.styles.Warning.Render(fmt.Sprintf("↻ reintento %s en %s", attempt, wait))
+	case streamStopped:
+		if !b.refreshKey.Enabled() {
+			return b.styles.Muted.Render("■ terminado")
+		}
+		return b.styles.Muted.Render(fmt.Sprintf("■ terminado ('%s' para relanzar)", b.refreshKey.Help().Key))
+	}
+	return ""
+}
 
-		return nil
+// formatDataTime muestra la hora si los datos son de hoy, o la fecha si no.
+func formatDataTime(t time.Time) string {
+	now := time.Now()
+	if t.Year() == now.Year() && t.YearDay() == now.YearDay() {
+		return t.Format("15:04:05")
 	}
+	return t.Format("Jan 2 15:04")
 }
@@ -0,0 +1,368 @@
+// blocks/shell_command/stream.go
+package shell_command
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/gas/fancy-welcome/logging"
+	"github.com/gas/fancy-welcome/shared/block"
+)
+
+const (
+	maxStreamBackoff = time.Minute      // Tope de la espera entre reintentos
+	streamStableTime = 30 * time.Second // Un proceso que aguanta esto vuelve a empezar la cuenta de reintentos
+	streamStopGrace  = time.Second      // Margen tras SIGTERM antes del SIGKILL
+	maxStreamLine    = 1024 * 1024      // Línea más larga que se lee entera
+)
+
+// Políticas de reinicio de un stream ('restart').
+const (
+	restartNever     = "never"
+	restartOnFailure = "on-failure"
+	restartAlways    = "always"
+)
+
+// streamOptions son las claves de un bloque en streaming.
+type streamOptions struct {
+	restart     string
+	maxRestarts int           // 0 = sin límite
+	backoff     time.Duration // Espera antes del primer reintento; se dobla en cada uno
+	batchLines  int           // Líneas por lote como mucho
+	batchWait   time.Duration // Lo que espera una línea antes de enviarse
+	pty         bool          // En una pseudo-terminal en lugar de con pipes
+}
+
+// streamOptionsFrom lee las opciones de streaming de la config del bloque.
+func streamOptionsFrom(blockConfig map[string]interface{}) streamOptions {
+	opts := streamOptions{
+		restart:     restartOnFailure,
+		maxRestarts: int(configNumber(blockConfig, "max_restarts", 0)),
+		backoff:     time.Duration(configNumber(blockConfig, "restart_backoff", 1) * float64(time.Second)),
+		batchLines:  int(configNumber(blockConfig, "batch_lines", 100)),
+		batchWait:   time.Duration(configNumber(blockConfig, "batch_ms", 50) * float64(time.Millisecond)),
+	}
+	if restart, ok := blockConfig["restart"].(string); ok {
+		opts.restart = restart
+	}
+	opts.pty, _ = blockConfig["pty"].(bool)
+	opts.backoff = max(opts.backoff, 100*time.Millisecond)
+	opts.batchLines = max(opts.batchLines, 1)
+	opts.batchWait = max(opts.batchWait, time.Millisecond)
+	return opts
+}
+
+// configNumber lee un número de la config (TOML da int64 o float64), o
+// 'fallback' si falta o no es positivo.
+func configNumber(blockConfig map[string]interface{}, key string, fallback float64) float64 {
+	var n float64
+	switch v := blockConfig[key].(type) {
+	case float64:
+		n = v
+	case int64:
+		n = float64(v)
+	}
+	if n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// shouldRestart decide si se relanza un proceso que ha terminado con 'err'
+// tras 'attempt' reintentos seguidos.
+func (o streamOptions) shouldRestart(err error, attempt int) bool {
+	if o.maxRestarts > 0 && attempt >= o.maxRestarts {
+		return false
+	}
+	switch o.restart {
+	case restartAlways:
+		return true
+	case restartOnFailure:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// delay es la espera antes del reintento 'attempt' (1, 2...): backoff,
+// 2*backoff, 4*backoff... hasta maxStreamBackoff.
+func (o streamOptions) delay(attempt int) time.Duration {
+	d := o.backoff
+	for i := 1; i < attempt && d < maxStreamBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxStreamBackoff)
+}
+
+// streamState es la fase en la que está un stream.
+type streamState int
+
+const (
+	streamStarting streamState = iota // Lanzando el proceso
+	streamRunning                     // Leyendo su salida
+	streamWaiting                     // Ha terminado; se relanza en retryAt
+	streamStopped                     // Ha terminado y no se relanza
+)
+
+// streamStateMsg cuenta al bloque cada cambio de fase de su stream.
+type streamStateMsg struct {
+	blockID string
+	state   streamState
+	err     error     // Por qué terminó el proceso (Waiting, Stopped)
+	attempt int       // Reintento en curso; 0 en el primer arranque (Starting, Waiting, Stopped)
+	retryAt time.Time // Cuándo se relanza (Waiting)
+}
+
+func (m streamStateMsg) BlockID() string { return m.blockID }
+
+// streamSupervisor lanza el comando de un bloque en streaming y lo vigila:
+// lee su salida (stdout y stderr, o la de su pseudo-terminal) en lotes, lo
+// relanza según la política del bloque y mata su grupo de procesos al parar.
+type streamSupervisor struct {
+	id      string
+	command string
+	sender  block.Sender
+	opts    streamOptions
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd     // Proceso en curso; nil entre reintentos
+	tty     *os.File      // Su pseudo-terminal, con pty = true
+	cols    int           // Ancho de la pseudo-terminal
+	exited  chan struct{} // Se cierra cuando 'cmd' termina
+	stop    chan struct{}
+	stopped bool
+}
+
+func newStreamSupervisor(id, command string, sender block.Sender, opts streamOptions, cols int) *streamSupervisor {
+	return &streamSupervisor{
+		id:      id,
+		command: command,
+		sender:  sender,
+		opts:    opts,
+		cols:    cols,
+		stop:    make(chan struct{}),
+	}
+}
+
+// SetWidth cambia el ancho de la pseudo-terminal, también la del proceso
+// en curso. Sin pty no hace nada.
+func (s *streamSupervisor) SetWidth(cols int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.opts.pty || cols == s.cols {
+		return
+	}
+	s.cols = cols
+	if s.tty != nil {
+		if err := resizePTY(s.tty, cols); err != nil {
+			logging.Log.Printf("[%s] Error resizing pty: %v", s.id, err)
+		}
+	}
+}
+
+// Start lanza el proceso y su vigilancia en segundo plano.
+func (s *streamSupervisor) Start() {
+	go s.run()
+}
+
+// Stop deja de relanzar el proceso y mata su grupo: primero con SIGTERM y,
+// si no ha terminado en streamStopGrace, con SIGKILL.
+func (s *streamSupervisor) Stop() error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	close(s.stop)
+	cmd, exited := s.cmd, s.exited
+	s.mu.Unlock()
+
+	if cmd == nil {
+		return nil
+	}
+	logging.Log.Printf("[%s] Killing stream process group...", s.id)
+	if err := terminateGroup(cmd); err != nil {
+		return err
+	}
+	select {
+	case <-exited:
+		return nil
+	case <-time.After(streamStopGrace):
+		return killGroup(cmd)
+	}
+}
+
+// run es el bucle de la vigilancia: lanza, lee y, al terminar, decide si
+// relanza tras la espera que toque.
+func (s *streamSupervisor) run() {
+	attempt := 0
+	for {
+		s.send(streamStateMsg{state: streamStarting, attempt: attempt})
+		started := time.Now()
+		err := s.runOnce()
+		if s.isStopped() {
+			return
+		}
+		if err != nil {
+			logging.Log.Printf("[%s] Stream ended: %v", s.id, err)
+		}
+		// Si ha aguantado un buen rato, el próximo fallo no arrastra los anteriores.
+		if time.Since(started) >= streamStableTime {
+			attempt = 0
+		}
+		if !s.opts.shouldRestart(err, attempt) {
+			s.send(streamStateMsg{state: streamStopped, err: err, attempt: attempt})
+			return
+		}
+
+		attempt++
+		delay := s.opts.delay(attempt)
+		s.send(streamStateMsg{state: streamWaiting, err: err, attempt: attempt, retryAt: time.Now().Add(delay)})
+		select {
+		case <-time.After(delay):
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// runOnce ejecuta el comando una vez y envía su salida en lotes hasta que
+// se cierra. Devuelve el error con el que termina (nil si sale con 0).
+func (s *streamSupervisor) runOnce() error {
+	cmd := exec.Command("sh", "-c", s.command)
+	r, err := s.start(cmd)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	s.mu.Lock()
+	s.cmd, s.exited = cmd, make(chan struct{})
+	exited, stopped := s.exited, s.stopped
+	s.mu.Unlock()
+	if stopped {
+		// Stop llegó mientras arrancaba.
+		killGroup(cmd)
+	} else {
+		s.send(streamStateMsg{state: streamRunning})
+	}
+
+	lines := make(chan string, s.opts.batchLines)
+	go s.readLines(r, lines)
+	s.batch(lines)
+
+	err = cmd.Wait()
+	close(exited)
+	s.mu.Lock()
+	s.cmd, s.tty = nil, nil
+	s.mu.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("falló la ejecución: %w", err)
+	}
+	return nil
+}
+
+// start lanza el proceso y devuelve de dónde leer su salida: su
+// pseudo-terminal o un pipe con stdout y stderr juntos, en el orden en que
+// se escriben.
+func (s *streamSupervisor) start(cmd *exec.Cmd) (io.ReadCloser, error) {
+	if s.opts.pty {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		tty, err := startPTY(cmd, s.cols)
+		s.tty = tty
+		return tty, err
+	}
+
+	setProcessGroup(cmd)
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout, cmd.Stderr = w, w
+	err = cmd.Start()
+	w.Close() // Sin nuestra copia, el pipe se cierra cuando termina el proceso
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// readLines lee la salida línea a línea hasta que se cierra el pipe. Si
+// una línea no cabe en el buffer, sigue vaciando el pipe para que el
+// proceso no se quede bloqueado escribiendo.
+func (s *streamSupervisor) readLines(r io.Reader, lines chan<- string) {
+	defer close(lines)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		if s.opts.pty {
+			lines <- cleanTTYLine(scanner.Text())
+		} else {
+			lines <- scanner.Text()
+		}
+	}
+	if err := scanner.Err(); err != nil && !ptyClosed(err) {
+		logging.Log.Printf("[%s] Error reading stream, discarding the rest: %v", s.id, err)
+		io.Copy(io.Discard, r)
+	}
+}
+
+// batch agrupa las líneas y las envía en un StreamLineBatchMsg cuando se
+// juntan batchLines o cuando la primera del lote lleva batchWait esperando.
+func (s *streamSupervisor) batch(lines <-chan string) {
+	var pending []string
+	var deadline <-chan time.Time
+	flush := func() {
+		if len(pending) > 0 {
+			s.send(block.NewStreamLineBatchMsg(s.id, pending))
+			pending = nil
+		}
+		deadline = nil
+	}
+
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				flush()
+				return
+			}
+			pending = append(pending, line)
+			if len(pending) >= s.opts.batchLines {
+				flush()
+			} else if deadline == nil {
+				deadline = time.After(s.opts.batchWait)
+			}
+		case <-deadline:
+			flush()
+		}
+	}
+}
+
+// send entrega un mensaje al programa, salvo que ya se haya parado.
+func (s *streamSupervisor) send(msg tea.Msg) {
+	if s.isStopped() {
+		return
+	}
+	if state, ok := msg.(streamStateMsg); ok {
+		state.blockID = s.id
+		msg = state
+	}
+	s.sender.Send(msg)
+}
+
+func (s *streamSupervisor) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
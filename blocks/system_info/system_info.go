@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"github.com/gas/fancy-welcome/config"
 	"github.com/gas/fancy-welcome/themes"
 	"github.com/gas/fancy-welcome/logging" // paquete de logging
@@ -17,13 +16,15 @@ import (
 
 type SystemInfoBlock struct {
 	id    			string
-	style 			lipgloss.Style
+	styles 		*themes.StyleSheet
 	info  			string
 	updateInterval 	time.Duration
     position     	string
 	rendererName   	string // <-- AÑADE ESTE CAMPO
 	blockConfig    	map[string]interface{}
 	isLoading      	bool
+	chrome         	block.Chrome
+	updatedAt      	time.Time
 }
 
 
@@ -41,6 +42,13 @@ func New() block.Block {
 	return &SystemInfoBlock{}
 }
 
+// ConfigSchema declara las claves de un bloque SystemInfo.
+func (b *SystemInfoBlock) ConfigSchema() block.Schema {
+	return block.Schema{Fields: []block.Field{
+		{Key: "update_seconds", Type: block.TypeNumber, Doc: "intervalo de refresco"},
+	}}
+}
+
 func (b *SystemInfoBlock) Name() string {
 	return b.id
 }
@@ -52,18 +60,34 @@ func (b *SystemInfoBlock) Position() string {
     return b.position
 }
 
+func (b *SystemInfoBlock) Styles() *themes.StyleSheet {
+	return b.styles
+}
+
+func (b *SystemInfoBlock) SetStyles(styles *themes.StyleSheet) {
+	b.styles = styles
+}
+
+// Status cumple block.Reporter.
+func (b *SystemInfoBlock) Status() block.Status {
+	status := block.Status{Chrome: b.chrome, UpdatedAt: b.updatedAt, Loading: b.isLoading}
+	if !b.isLoading {
+		status.NextRefresh = block.NextTick(b.id)
+	}
+	return status
+}
+
 func (b *SystemInfoBlock) RendererName() string {
     return "raw_text" // O el que corresponda
 }
 
-func (b *SystemInfoBlock) Init(blockConfig map[string]interface{}, globalConfig config.GeneralConfig, theme *themes.Theme) error {
-    logging.Log.Printf("SI Init: [%s] received msg: %T", b.id)
+func (b *SystemInfoBlock) Init(blockConfig map[string]interface{}, globalConfig config.GeneralConfig, styles *themes.StyleSheet) error {
 	b.blockConfig = blockConfig
-	b.id = blockConfig["name"].(string)
+	b.id, _ = blockConfig["name"].(string)
+	logging.Log.Printf("[%s] Initializing block...", b.id)
     b.position, _ = blockConfig["position"].(string)
-	b.style = lipgloss.NewStyle().
-		Background(lipgloss.Color(theme.Colors.Background)).
-		Foreground(lipgloss.Color(theme.Colors.Text))	
+	b.styles = styles
+	b.chrome = block.ChromeFrom(blockConfig)
 
 	var updateSecs float64 = 0
 	// Se busca la clave "update_seconds".
@@ -134,6 +158,7 @@ func (b *SystemInfoBlock) Update(msg tea.Msg) (block.Block, tea.Cmd) {
 		if m.blockID == b.id {
 			b.isLoading = false
 			b.info = m.info
+			b.updatedAt = time.Now()
 			// Programamos la siguiente actualización.
 			return b, block.ScheduleNextTick(b.id, b.updateInterval)
 		}
@@ -144,11 +169,11 @@ func (b *SystemInfoBlock) Update(msg tea.Msg) (block.Block, tea.Cmd) {
 
 func (b *SystemInfoBlock) View() string {
 	if b.info == "" && b.isLoading {
-		return b.style.Render("Loading system info...")
+		return b.styles.Base.Render("Loading system info...")
 	}
 
 	if b.info == "" {
-		return b.style.Render("...")
+		return b.styles.Base.Render("...")
 	}
-	return b.style.Render(b.info)
+	return b.styles.Base.Render(b.info)
 }
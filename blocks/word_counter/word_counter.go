@@ -3,28 +3,38 @@ package word_counter
 import (
     "fmt"
     "strings"
-    //"time"
+    "time"
 
     "github.com/charmbracelet/bubbletea"
-    "github.com/charmbracelet/lipgloss" // Importamos lipgloss para el estilo
     "github.com/gas/fancy-welcome/config"
     "github.com/gas/fancy-welcome/shared/block"
+    "github.com/gas/fancy-welcome/shared/data"
     "github.com/gas/fancy-welcome/themes"
 )
 
 // Struct más limpio, solo con los campos necesarios.
 type WordCounterBlock struct {
     id          string
-    style       lipgloss.Style
+    styles      *themes.StyleSheet
     listensTo   string // A qué bloque escucha
     countString string // Qué palabra o texto buscar
     count       int    // Su estado interno
     matchingLines []string // <-- AÑADIMOS ESTE CAMPO
     position    string
+    chrome      block.Chrome
+    updatedAt   time.Time // Cuándo llegó la última salida del bloque escuchado
 }
 
 func New() block.Block { return &WordCounterBlock{} }
 
+// ConfigSchema declara las claves de un bloque WordCounter.
+func (b *WordCounterBlock) ConfigSchema() block.Schema {
+    return block.Schema{Fields: []block.Field{
+        {Key: "listens_to", Type: block.TypeBlockRef, Required: true, Doc: "bloque cuya salida se cuenta"},
+        {Key: "count_string", Type: block.TypeString, Required: true, Doc: "texto a buscar"},
+    }}
+}
+
 // Método de block implementado aquí para manejarlo
 // ExpandedView devuelve el contenido detallado para la vista expandida.
 func (b *WordCounterBlock) ExpandedView() string {
@@ -44,22 +54,34 @@ func (b *WordCounterBlock) Position() string {
     return b.position
 }
 
+func (b *WordCounterBlock) Styles() *themes.StyleSheet {
+    return b.styles
+}
+
+func (b *WordCounterBlock) SetStyles(styles *themes.StyleSheet) {
+    b.styles = styles
+}
+
+// Status cumple block.Reporter.
+func (b *WordCounterBlock) Status() block.Status {
+    return block.Status{Chrome: b.chrome, UpdatedAt: b.updatedAt}
+}
+
 func (b *WordCounterBlock) RendererName() string {
     // No usa un renderer complejo, pero cumplimos el contrato.
     return "raw_text"
 }
 
-func (b *WordCounterBlock) Init(blockConfig map[string]interface{}, globalConfig config.GeneralConfig, theme *themes.Theme) error {
-    b.id = blockConfig["name"].(string)
+func (b *WordCounterBlock) Init(blockConfig map[string]interface{}, globalConfig config.GeneralConfig, styles *themes.StyleSheet) error {
+    b.id, _ = blockConfig["name"].(string)
     b.listensTo, _ = blockConfig["listens_to"].(string)
     b.countString, _ = blockConfig["count_string"].(string)
     b.position, _ = blockConfig["position"].(string)
     b.count = 0
+    b.chrome = block.ChromeFrom(blockConfig)
     
     // Aunque no lo usemos mucho, es bueno tener un estilo base.
-    b.style = lipgloss.NewStyle().
-        Background(lipgloss.Color(theme.Colors.Background)).
-        Foreground(lipgloss.Color(theme.Colors.Text))
+    b.styles = styles
         
     return nil
 }
@@ -69,23 +91,13 @@ func (b *WordCounterBlock) Update(msg tea.Msg) (block.Block, tea.Cmd) {
     case block.TeeOutputMsg:
         // Tu lógica, que es perfecta, se mantiene.
         if m.SourceBlockID == b.listensTo {
-            // Hacemos un type switch para ver qué tipo 
-            // de datos nos han enviado. string o []string
-            switch data := m.Output.(type) {
-            case string:
-                if text, ok := m.Output.(string); ok {
-                    if strings.Contains(text, b.countString) {
-                        b.count++
-                        b.matchingLines = append(b.matchingLines, data)
-                    }
-                }
-            case []string:
-                // Caso 2: Recibimos un lote de líneas (de un comando en streaming)
-                for _, line := range data {
-                    if strings.Contains(line, b.countString) {
-                        b.count++
-                        b.matchingLines = append(b.matchingLines, line)
-                    }
+            b.updatedAt = time.Now()
+            // Sea cual sea el tipo de datos (texto, lote de un stream,
+            // tabla...), lo recorremos como líneas.
+            for _, line := range data.AsLines(m.Output) {
+                if strings.Contains(line, b.countString) {
+                    b.count++
+                    b.matchingLines = append(b.matchingLines, line)
                 }
             }
         }
@@ -108,5 +120,5 @@ func (b *WordCounterBlock) Update(msg tea.Msg) (block.Block, tea.Cmd) {
 func (b *WordCounterBlock) View() string {
     // Aplicamos el estilo base al texto.
     viewString := fmt.Sprintf("'%s' Visto en '%s': %d veces", b.countString, b.listensTo, b.count)
-    return b.style.Render(viewString)
+    return b.styles.Base.Render(viewString)
 }
\ No newline at end of file
@@ -0,0 +1,54 @@
+// cache/cache.go
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gas/fancy-welcome/utils"
+)
+
+// Dir devuelve el directorio de la caché: ~/.cache/fancy-welcome.
+func Dir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".cache", "fancy-welcome")
+}
+
+// FilePath devuelve el archivo de caché de un bloque.
+func FilePath(blockName string) string {
+	return filepath.Join(Dir(), fmt.Sprintf("%s.json", blockName))
+}
+
+// Load lee la caché de un bloque en 'v'. Si no existe, devuelve un error
+// que cumple os.IsNotExist.
+func Load(blockName string, v interface{}) error {
+	data, err := os.ReadFile(FilePath(blockName))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("caché corrupta para '%s': %w", blockName, err)
+	}
+	return nil
+}
+
+// Save escribe la caché de un bloque de forma atómica, para que una lectura
+// concurrente (otra sesión abriéndose) nunca vea un JSON a medias.
+func Save(blockName string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("no se pudo serializar la caché de '%s': %w", blockName, err)
+	}
+	return utils.WriteFileAtomic(FilePath(blockName), data, 0644)
+}
+
+// Remove borra la caché de un bloque. No es un error que no exista.
+func Remove(blockName string) error {
+	err := os.Remove(FilePath(blockName))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
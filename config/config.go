@@ -2,46 +2,231 @@
 package config
 
 import (
+	_ "embed"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/pelletier/go-toml/v2"
 )
 
+// EnvConfigPath es la variable de entorno que fija el archivo de configuración.
+const EnvConfigPath = "FANCY_WELCOME_CONFIG"
+
+const fileName = "fancy_welcome.toml"
+
+// systemPath es la configuración del sistema, para todos los usuarios.
+var systemPath = filepath.Join("/etc", "fancy-welcome", fileName)
+
+//go:embed default.toml
+var defaultConfig []byte
+
+// explicitPath es el archivo indicado con --config, si lo hay.
+var explicitPath string
+
 type GeneralConfig struct {
 	EnabledBlocksOrder []string `toml:"enabled_blocks_order"`
 	GlobalUpdateSeconds float64  `toml:"global_update_seconds"` // Update time de la app
+	TTYTimeoutSeconds   float64  `toml:"tty_timeout_seconds"`   // Espera máxima por bloque en modo --simple
+	StatusBar           *bool    `toml:"status_bar"`            // Barra de estado de la TUI (por defecto, sí)
+	Layout              string   `toml:"layout"`                // Preset de [layouts] (o uno integrado)
+	HiddenPageRefresh   float64  `toml:"hidden_page_refresh"`   // Cuántas veces más despacio se refrescan las páginas que no se ven
+}
+
+// ShowStatusBar indica si la TUI muestra la barra de estado.
+func (g GeneralConfig) ShowStatusBar() bool {
+	return g.StatusBar == nil || *g.StatusBar
 }
 
 type ThemeConfig struct {
 	SelectedTheme string `toml:"selected_theme"`
+	Background    string `toml:"background"` // "auto", "dark" o "light": elige la variante del tema
+}
+
+// LayoutConfig es un preset de layout, una tabla [layouts.<nombre>].
+// Cada columna es un ancho fijo en celdas (30) o una fracción del sitio
+// que queda ("1fr", "2fr").
+type LayoutConfig struct {
+	Columns []interface{} `toml:"columns"`
+}
+
+// BreakpointConfig es una regla [[breakpoints]]: con la terminal más
+// estrecha que 'Below' columnas se usa otro layout y se ocultan los bloques
+// con menos prioridad que 'MinPriority'.
+type BreakpointConfig struct {
+	Below       int    `toml:"below"`
+	Layout      string `toml:"layout"`       // "" = el de [general]
+	MinPriority string `toml:"min_priority"` // "", "normal" o "high"
+}
+
+// PageConfig es una página (pestaña) del dashboard, una tabla [pages.<nombre>].
+// Las páginas salen en el orden del archivo. Solo las usa el modo welcome.
+type PageConfig struct {
+	Title  string   `toml:"title"`  // Nombre en la barra de pestañas; por defecto, el de la tabla
+	Blocks []string `toml:"blocks"` // Bloques de la página, en orden
+	Layout string   `toml:"layout"` // Preset de layout; "" = el de [general]
 }
 
 type Config struct {
-	General GeneralConfig            `toml:"general"`
-	Theme   ThemeConfig              `toml:"theme"`
-	Blocks  map[string]interface{}   `toml:"blocks"`
+	General     GeneralConfig            `toml:"general"`
+	Theme       ThemeConfig              `toml:"theme"`
+	Layouts     map[string]LayoutConfig  `toml:"layouts"`
+	Breakpoints []BreakpointConfig       `toml:"breakpoints"`
+	Pages       map[string]PageConfig    `toml:"pages"`
+	Keys        map[string]interface{}   `toml:"keys"` // Teclas de cada acción: una o una lista
+	Blocks      map[string]interface{}   `toml:"blocks"`
+	Path        string                   `toml:"-"` // Archivo del que se cargó ("" si es la integrada)
+	raw         []byte                   // Contenido original, para copiarlo al guardar en otro sitio
+}
+
+// SetPath fija el archivo de configuración (flag global --config). Tiene
+// prioridad sobre la variable de entorno y las rutas por defecto.
+func SetPath(path string) {
+	explicitPath = path
+}
+
+// UserPath devuelve el archivo de configuración del usuario:
+// $XDG_CONFIG_HOME/fancy-welcome/fancy_welcome.toml o ~/.config/fancy-welcome/...
+func UserPath() (string, error) {
+	dirs := userDirs()
+	if len(dirs) == 0 {
+		return "", fmt.Errorf("no se pudo obtener el directorio home")
+	}
+	return filepath.Join(dirs[0], fileName), nil
+}
+
+// userDirs devuelve los directorios de configuración del usuario en orden
+// de prioridad: $XDG_CONFIG_HOME/fancy-welcome y ~/.config/fancy-welcome.
+func userDirs() []string {
+	var dirs []string
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		dirs = append(dirs, filepath.Join(xdg, "fancy-welcome"))
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(homeDir, ".config", "fancy-welcome"))
+	}
+	return dirs
+}
+
+// Dirs devuelve todos los directorios de configuración en orden de
+// prioridad: los del usuario y luego el del sistema. Otros recursos (los
+// temas, por ejemplo) se buscan en subdirectorios de estos.
+func Dirs() []string {
+	return append(userDirs(), filepath.Dir(systemPath))
+}
+
+// searchPaths devuelve las rutas candidatas en orden de prioridad.
+func searchPaths() []string {
+	var paths []string
+	for _, dir := range Dirs() {
+		paths = append(paths, filepath.Join(dir, fileName))
+	}
+	return paths
+}
+
+// Resolve decide qué archivo de configuración usar: --config, luego
+// $FANCY_WELCOME_CONFIG y luego las rutas de searchPaths. Devuelve "" si no
+// hay ninguno y hay que usar la configuración integrada. Una ruta indicada
+// explícitamente (flag o variable) que no existe es un error.
+func Resolve() (string, error) {
+	for _, explicit := range []struct{ path, origin string }{
+		{explicitPath, "--config"},
+		{os.Getenv(EnvConfigPath), EnvConfigPath},
+	} {
+		if explicit.path == "" {
+			continue
+		}
+		if _, err := os.Stat(explicit.path); err != nil {
+			return "", fmt.Errorf("no se pudo leer el archivo de configuración %s (%s): %w", explicit.path, explicit.origin, err)
+		}
+		return explicit.path, nil
+	}
+
+	for _, path := range searchPaths() {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", nil
 }
 
 func LoadConfig() (*Config, error) {
-	homeDir, err := os.UserHomeDir()
+	configPath, err := Resolve()
 	if err != nil {
-		return nil, fmt.Errorf("no se pudo obtener el directorio home: %w", err)
+		return nil, err
 	}
 
-	configPath := filepath.Join(homeDir, ".config", "fancy-welcome", "fancy_welcome.toml")
-	
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return nil, fmt.Errorf("no se pudo leer el archivo de configuración %s: %w", configPath, err)
+	data := defaultConfig
+	if configPath != "" {
+		data, err = os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer el archivo de configuración %s: %w", configPath, err)
+		}
 	}
 
-	var cfg Config
+	cfg := Config{Path: configPath, raw: data}
 	err = toml.Unmarshal(data, &cfg)
 	if err != nil {
-		return nil, fmt.Errorf("no se pudo parsear el TOML de configuración: %w", err)
+		// Con la posición del error, para ir directos a la línea.
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			row, col := decodeErr.Position()
+			return nil, fmt.Errorf("%s:%d:%d: TOML inválido: %w", cfg.Source(), row, col, err)
+		}
+		return nil, fmt.Errorf("no se pudo parsear el TOML de configuración (%s): %w", cfg.Source(), err)
 	}
 
 	return &cfg, nil
 }
+
+// Source describe de dónde salió la config, para los mensajes de error.
+func (c *Config) Source() string {
+	if c.Path == "" {
+		return "(configuración integrada)"
+	}
+	return c.Path
+}
+
+// Line devuelve la línea (desde 1) donde se define 'key' dentro de la tabla
+// 'table' (p. ej. "blocks.disk"), o la de la cabecera de la tabla si key es
+// "" o no aparece. Las entradas de un array de tablas se nombran por su
+// posición: "breakpoints[0]" es el primer [[breakpoints]]. Devuelve 0 si la
+// tabla no está en el archivo.
+func (c *Config) Line(table, key string) int {
+	headerLine := 0
+	current := ""
+	entries := make(map[string]int) // Entradas vistas de cada array de tablas
+	for i, line := range strings.Split(string(c.raw), "\n") {
+		trimmed := strings.TrimSpace(stripComment(line))
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			current = strings.TrimSpace(strings.Trim(trimmed, "[]"))
+			if strings.HasPrefix(trimmed, "[[") {
+				name := current
+				current = fmt.Sprintf("%s[%d]", name, entries[name])
+				entries[name]++
+			}
+			if current == table {
+				headerLine = i + 1
+			}
+			continue
+		}
+		if current != table || key == "" {
+			continue
+		}
+		if name, _, ok := strings.Cut(trimmed, "="); ok && strings.Trim(strings.TrimSpace(name), `"'`) == key {
+			return i + 1
+		}
+	}
+	return headerLine
+}
+
+// WritablePath devuelve dónde se guardan los cambios: el propio archivo si
+// es del usuario, o el del usuario si se cargó la integrada o la de /etc.
+func (c *Config) WritablePath() (string, error) {
+	if c.Path != "" && c.Path != systemPath {
+		return c.Path, nil
+	}
+	return UserPath()
+}
@@ -0,0 +1,257 @@
+// config/save.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/gas/fancy-welcome/utils"
+)
+
+// BlockDef es un bloque nuevo que se añade al archivo de configuración.
+// Keys fija el orden en que se escriben sus claves.
+type BlockDef struct {
+	Name   string
+	Keys   []string
+	Values map[string]interface{}
+}
+
+var (
+	headerPattern = regexp.MustCompile(`^\s*\[`)
+	orderPattern  = regexp.MustCompile(`^(\s*)enabled_blocks_order\s*=`)
+)
+
+// SaveLayout actualiza el archivo de configuración en 'path': reescribe
+// 'enabled_blocks_order', elimina las secciones de los bloques 'removed' y
+// añade al final las de 'added'. El resto del archivo (comentarios, orden,
+// formato) se conserva tal cual, por eso se edita como texto y no se
+// vuelve a serializar el TOML entero.
+func SaveLayout(path string, order []string, added []BlockDef, removed []string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("no se pudo leer el archivo de configuración %s: %w", path, err)
+	}
+	lines := strings.Split(string(data), "\n")
+
+	for _, name := range removed {
+		lines = removeBlockSection(lines, name)
+	}
+
+	lines, err = replaceOrder(lines, order)
+	if err != nil {
+		return err
+	}
+
+	for _, def := range added {
+		section, err := renderBlockSection(def)
+		if err != nil {
+			return err
+		}
+		// Una línea en blanco de separación con lo anterior.
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		lines = append(lines, "")
+		lines = append(lines, section...)
+	}
+
+	content := strings.Join(lines, "\n")
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return utils.WriteFileAtomic(path, []byte(content), 0644)
+}
+
+// SaveLayout guarda los cambios de layout en WritablePath. Si la config se
+// cargó de otro sitio (la integrada o la de /etc), primero la copia allí
+// para no perder el resto de bloques. Devuelve el archivo escrito.
+func (c *Config) SaveLayout(order []string, added []BlockDef, removed []string) (string, error) {
+	target, err := c.WritablePath()
+	if err != nil {
+		return "", err
+	}
+	if target != c.Path {
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			if err := utils.WriteFileAtomic(target, c.raw, 0644); err != nil {
+				return "", fmt.Errorf("no se pudo crear %s: %w", target, err)
+			}
+		}
+	}
+	if err := SaveLayout(target, order, added, removed); err != nil {
+		return "", err
+	}
+	c.Path = target
+	return target, nil
+}
+
+// removeBlockSection quita la tabla [blocks.<name>] y sus subtablas, junto
+// con los comentarios pegados a su cabecera. Los comentarios pegados a la
+// cabecera siguiente se quedan con ella.
+func removeBlockSection(lines []string, name string) []string {
+	header := "[blocks." + name + "]"
+	subPrefix := "[blocks." + name + "."
+
+	isOwnHeader := func(line string) bool {
+		trimmed := strings.TrimSpace(line)
+		return strings.HasPrefix(trimmed, header) || strings.HasPrefix(trimmed, subPrefix)
+	}
+
+	var out []string
+	for i := 0; i < len(lines); {
+		if !isOwnHeader(lines[i]) {
+			out = append(out, lines[i])
+			i++
+			continue
+		}
+
+		// Los comentarios justo encima de la cabecera son de este bloque.
+		for len(out) > 0 && strings.HasPrefix(strings.TrimSpace(out[len(out)-1]), "#") {
+			out = out[:len(out)-1]
+		}
+
+		// Buscamos el final de la sección: la siguiente cabecera ajena.
+		end := i + 1
+		for end < len(lines) && !(headerPattern.MatchString(lines[end]) && !isOwnHeader(lines[end])) {
+			end++
+		}
+		// Retrocedemos sobre los comentarios que preceden a esa cabecera.
+		keep := end
+		for keep > i+1 && strings.HasPrefix(strings.TrimSpace(lines[keep-1]), "#") {
+			keep--
+		}
+		out = append(out, lines[keep:end]...)
+		i = end
+	}
+	return out
+}
+
+// replaceOrder reescribe 'enabled_blocks_order' respetando si estaba en una
+// o en varias líneas. Si no existe, lo añade a [general].
+func replaceOrder(lines []string, order []string) ([]string, error) {
+	quoted := make([]string, len(order))
+	for i, name := range order {
+		quoted[i] = quoteString(name)
+	}
+
+	for i, line := range lines {
+		m := orderPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		indent := m[1]
+
+		// El array termina en la línea donde se cierran los corchetes.
+		end := i
+		depth := 0
+		for end < len(lines) {
+			depth += strings.Count(stripComment(lines[end]), "[") - strings.Count(stripComment(lines[end]), "]")
+			if depth <= 0 {
+				break
+			}
+			end++
+		}
+		if end >= len(lines) {
+			return nil, fmt.Errorf("'enabled_blocks_order' no está bien cerrado")
+		}
+
+		var replacement []string
+		if end == i {
+			replacement = []string{fmt.Sprintf("%senabled_blocks_order = [%s]", indent, strings.Join(quoted, ", "))}
+		} else {
+			itemIndent := indent + "    "
+			if i+1 < end {
+				itemIndent = leadingSpace(lines[i+1])
+			}
+			replacement = append(replacement, indent+"enabled_blocks_order = [")
+			for _, q := range quoted {
+				replacement = append(replacement, itemIndent+q+",")
+			}
+			replacement = append(replacement, indent+"]")
+		}
+
+		out := append([]string{}, lines[:i]...)
+		out = append(out, replacement...)
+		return append(out, lines[end+1:]...), nil
+	}
+
+	orderLine := fmt.Sprintf("enabled_blocks_order = [%s]", strings.Join(quoted, ", "))
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "[general]" {
+			out := append([]string{}, lines[:i+1]...)
+			out = append(out, orderLine)
+			return append(out, lines[i+1:]...), nil
+		}
+	}
+	return append([]string{"[general]", orderLine, ""}, lines...), nil
+}
+
+// renderBlockSection genera la tabla TOML de un bloque nuevo.
+func renderBlockSection(def BlockDef) ([]string, error) {
+	section := []string{fmt.Sprintf("[blocks.%s]", def.Name)}
+	for _, key := range def.Keys {
+		value, ok := def.Values[key]
+		if !ok {
+			continue
+		}
+		// Los strings con comillas dobles, como en un archivo escrito a mano;
+		// para el resto de valores dejamos que go-toml se ocupe del formato.
+		if str, ok := value.(string); ok {
+			section = append(section, fmt.Sprintf("%s = %s", key, quoteString(str)))
+			continue
+		}
+		v, err := toml.Marshal(map[string]interface{}{key: value})
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo serializar '%s' del bloque '%s': %w", key, def.Name, err)
+		}
+		section = append(section, strings.TrimSpace(string(v)))
+	}
+	return section, nil
+}
+
+// quoteString devuelve un string básico de TOML entre comillas dobles.
+func quoteString(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&builder, `\u%04X`, r)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// stripComment quita el comentario final de una línea (ignorando '#' dentro de comillas).
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
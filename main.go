@@ -19,9 +19,9 @@ import (
 	//"github.com/gas/fancy-welcome/blocks/system_info"
 	//"github.com/gas/fancy-welcome/blocks/word_counter"
     //"github.com/gas/fancy-welcome/blocks/filter"
-	//"github.com/gas/fancy-welcome/config"
+	"github.com/gas/fancy-welcome/config"
 	//"github.com/gas/fancy-welcome/shared/block"
-	//"github.com/gas/fancy-welcome/themes"
+	"github.com/gas/fancy-welcome/themes"
 
 	"github.com/gas/fancy-welcome/logging"
 	"github.com/gas/fancy-welcome/modes" 
@@ -45,6 +45,24 @@ func main() {
 	app := &cli.App{
 		Name:  "fancy-cli",
 		Usage: "Una herramienta TUI modular y extensible",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "config",
+				Usage: "Archivo de configuración (por defecto: $" + config.EnvConfigPath + ", $XDG_CONFIG_HOME, ~/.config o /etc/fancy-welcome).",
+			},
+			&cli.StringFlag{
+				Name:  "color",
+				Value: themes.ColorAuto,
+				Usage: "Cuándo usar colores: auto (según la terminal y NO_COLOR), never o always.",
+			},
+		},
+		Before: func(c *cli.Context) error {
+			config.SetPath(c.String("config"))
+			if err := themes.SetColorMode(c.String("color")); err != nil {
+				return cli.Exit(err, 2)
+			}
+			return nil
+		},
 		Commands: []*cli.Command{
 			{
 				Name:    "welcome",
@@ -72,15 +90,79 @@ func main() {
 				Usage:   "Visualiza datos y añade filtros dinámicamente",
 				Flags: []cli.Flag{
 					simpleOutputFlag, // El modo 'filter' también tiene la opción de salida simple
+					// Flags solo para --simple: filtrar desde scripts y cron.
+					&cli.StringFlag{
+						Name:  "source",
+						Usage: "Bloque de la configuración cuya salida se filtra ('-' o vacío para stdin).",
+					},
+					&cli.StringSliceFlag{
+						Name:    "expr",
+						Aliases: []string{"e"},
+						Usage:   "Filtro al estilo grep (ej: '-i -v ERROR'). Se puede repetir; se aplican en cadena.",
+					},
+					&cli.StringSliceFlag{
+						Name:    "block",
+						Aliases: []string{"b"},
+						Usage:   "Reutiliza el filtro (y la fuente) de un bloque Filter de la configuración.",
+					},
+					&cli.BoolFlag{
+						Name:  "highlight",
+						Usage: "Resalta las coincidencias.",
+					},
+					&cli.BoolFlag{
+						Name:    "count",
+						Aliases: []string{"c"},
+						Usage:   "Muestra solo el número de coincidencias.",
+					},
 				},
 				Action: func(c *cli.Context) error {
 					// La acción de 'filter' hace lo mismo
 					if c.Bool("simple") {
-						return modes.RunFilterTTY()
+						return modes.RunFilterTTY(modes.FilterTTYOptions{
+							Source:      c.String("source"),
+							Expressions: c.StringSlice("expr"),
+							Blocks:      c.StringSlice("block"),
+							Highlight:   c.Bool("highlight"),
+							Count:       c.Bool("count"),
+						})
 					}
 					return modes.RunFilterTUI()
 				},
 			},
+			{
+				Name:  "themes",
+				Usage: "Lista y muestra los temas disponibles",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "list",
+						Usage: "Lista los temas integrados y los de los directorios de temas",
+						Action: func(c *cli.Context) error {
+							return modes.RunThemesList()
+						},
+					},
+					{
+						Name:      "show",
+						Usage:     "Muestra la paleta de un tema",
+						ArgsUsage: "<tema>",
+						Action: func(c *cli.Context) error {
+							return modes.RunThemesShow(c.Args().First())
+						},
+					},
+				},
+			},
+			{
+				Name:  "config",
+				Usage: "Utilidades para el archivo de configuración",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "validate",
+						Usage: "Comprueba la configuración y muestra todos los problemas con su línea",
+						Action: func(c *cli.Context) error {
+							return modes.RunConfigValidate()
+						},
+					},
+				},
+			},
 		},
 	}
 
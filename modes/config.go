@@ -0,0 +1,40 @@
+// modes/config.go
+package modes
+
+import (
+    "fmt"
+    "os"
+
+    "github.com/urfave/cli/v2"
+
+    "github.com/gas/fancy-welcome/config"
+    "github.com/gas/fancy-welcome/shared"
+    "github.com/gas/fancy-welcome/themes"
+)
+
+// RunConfigValidate comprueba la configuración sin inicializar ningún bloque
+// (no ejecuta comandos) e informa de todos los problemas a la vez.
+// Termina con código 1 si hay algún error; los avisos no cuentan.
+func RunConfigValidate() error {
+    cfg, err := config.LoadConfig()
+    if err != nil {
+        return cli.Exit(err.Error(), 1)
+    }
+
+    problems := shared.Validate(cfg, shared.NewBlockFactory())
+    if _, err := themes.LoadTheme(cfg.Theme.SelectedTheme); err != nil {
+        problems = append(problems, shared.Problem{
+            File:    cfg.Source(),
+            Line:    cfg.Line("theme", "selected_theme"),
+            Key:     "selected_theme",
+            Message: err.Error(),
+        })
+    }
+
+    shared.ReportProblems(os.Stdout, problems)
+    if shared.HasErrors(problems) {
+        return cli.Exit(fmt.Sprintf("%s: la configuración tiene errores", cfg.Source()), 1)
+    }
+    fmt.Printf("%s: configuración válida (%d bloques)\n", cfg.Source(), len(cfg.Blocks))
+    return nil
+}
@@ -0,0 +1,483 @@
+// modes/expanded.go
+package modes
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "strings"
+    "unicode"
+
+    "github.com/aymanbagabas/go-osc52/v2"
+    "github.com/charmbracelet/bubbles/key"
+    "github.com/charmbracelet/bubbles/textinput"
+    "github.com/charmbracelet/bubbles/viewport"
+    "github.com/charmbracelet/bubbletea"
+    "github.com/charmbracelet/lipgloss"
+    "github.com/charmbracelet/x/ansi"
+
+    "github.com/gas/fancy-welcome/shared"
+    "github.com/gas/fancy-welcome/shared/block"
+    "github.com/gas/fancy-welcome/themes"
+)
+
+// Lo que se está escribiendo abajo de la vista expandida.
+const (
+    promptNone = iota
+    promptSearch
+    promptFilter
+    promptSave
+)
+
+// match es una coincidencia de la búsqueda: la línea (de las que se ven) y
+// sus columnas, en celdas.
+type match struct {
+    line, start, end int
+}
+
+// expandedView es la vista expandida de un bloque: su contenido completo
+// en un viewport, con búsqueda ('/', n/N), filtro de líneas, guardado en
+// un archivo y copia al portapapeles. La de un stream sigue el final
+// mientras no se pause.
+type expandedView struct {
+    block    block.Block
+    viewport viewport.Model
+    input    textinput.Model
+    keys     *shared.KeyMap
+    styles   *themes.StyleSheet
+    canSave  bool // El modo welcome es de solo lectura: no guarda archivos
+    height   int
+
+    lines    []string // Contenido del bloque, con sus colores
+    shown    []string // Las líneas que pasan el filtro
+    prompt   int
+    previous string // Búsqueda o filtro de antes de abrir el prompt, para cancelar
+    query    string // Búsqueda: se resaltan sus coincidencias
+    filter   string // Solo se ven las líneas que lo contienen
+    matches  []match
+    current  int  // Coincidencia seleccionada
+    colors   bool // Al guardar, con los colores (ANSI)
+    live     bool // El bloque crece por el final (block.Follower)
+    follow   bool // La vista va al final con cada línea nueva
+}
+
+// newExpandedView abre la vista expandida de 'b' en un área de width x height.
+func newExpandedView(b block.Block, keys *shared.KeyMap, styles *themes.StyleSheet, canSave bool, width, height int) *expandedView {
+    vp := viewport.New(width, height)
+    vp.Style = styles.Base
+    vp.KeyMap = keys.ViewportKeys()
+
+    e := &expandedView{
+        block:    b,
+        viewport: vp,
+        input:    textinput.New(),
+        keys:     keys,
+        styles:   styles,
+        canSave:  canSave,
+    }
+    if follower, ok := b.(block.Follower); ok && follower.Follows() {
+        e.live, e.follow = true, true
+    }
+    e.setSize(width, height)
+    e.refresh()
+    return e
+}
+
+// setSize ajusta la vista al área que tiene.
+func (e *expandedView) setSize(width, height int) {
+    e.viewport.Width = width
+    e.height = height
+    e.layoutViewport()
+}
+
+// setStyles cambia los estilos (tras cambiar de tema).
+func (e *expandedView) setStyles(keys *shared.KeyMap, styles *themes.StyleSheet) {
+    e.keys, e.styles = keys, styles
+    e.viewport.Style = styles.Base
+    e.viewport.KeyMap = keys.ViewportKeys()
+    e.render()
+}
+
+// refresh vuelve a leer el contenido del bloque (que puede haber cambiado)
+// sin mover la vista, salvo que esté siguiendo el final.
+func (e *expandedView) refresh() {
+    content := e.block.View()
+    if expander, ok := e.block.(block.Expander); ok {
+        content = expander.ExpandedView()
+    }
+    e.lines = strings.Split(content, "\n")
+    e.apply()
+    if e.follow {
+        e.viewport.GotoBottom()
+    }
+}
+
+// typing indica si se está escribiendo en el prompt: las teclas son texto.
+func (e *expandedView) typing() bool {
+    return e.prompt != promptNone
+}
+
+// Update atiende un mensaje. Devuelve true si la vista pide cerrarse.
+func (e *expandedView) Update(msg tea.Msg) (tea.Cmd, bool) {
+    switch msg := msg.(type) {
+    case tea.KeyMsg:
+        if e.typing() {
+            return e.updatePrompt(msg), false
+        }
+        switch {
+        case key.Matches(msg, e.keys.Back):
+            return nil, true
+        case key.Matches(msg, e.keys.Search):
+            return e.openPrompt(promptSearch, "/", e.query), false
+        case key.Matches(msg, e.keys.FilterLines):
+            return e.openPrompt(promptFilter, "filtro: ", e.filter), false
+        case key.Matches(msg, e.keys.NextMatch):
+            e.step(1)
+        case key.Matches(msg, e.keys.PrevMatch):
+            e.step(-1)
+        case e.canSave && key.Matches(msg, e.keys.Save):
+            return e.openPrompt(promptSave, "guardar en: ", e.block.Name()+"_expanded_view.txt"), false
+        case key.Matches(msg, e.keys.Copy):
+            return e.clipboard(), false
+        case key.Matches(msg, e.keys.Top):
+            e.viewport.GotoTop()
+            e.updateFollow()
+        case key.Matches(msg, e.keys.Bottom):
+            e.viewport.GotoBottom()
+            e.updateFollow()
+        case e.live && key.Matches(msg, e.keys.Follow):
+            e.follow = !e.follow
+            if e.follow {
+                e.viewport.GotoBottom()
+            }
+            return nil, false
+        default:
+            var cmd tea.Cmd
+            e.viewport, cmd = e.viewport.Update(msg)
+            e.updateFollow()
+            return cmd, false
+        }
+        return nil, false
+
+    case tea.MouseMsg:
+        var cmd tea.Cmd
+        e.viewport, cmd = e.viewport.Update(msg)
+        e.updateFollow()
+        return cmd, false
+    }
+
+    // El resto (el parpadeo del cursor, por ejemplo) es para el prompt.
+    if e.typing() {
+        var cmd tea.Cmd
+        e.input, cmd = e.input.Update(msg)
+        return cmd, false
+    }
+    return nil, false
+}
+
+// updateFollow sigue el final de un stream si la vista está en él, y lo
+// pausa si no: moverse hacia arriba lo pausa y volver al final lo sigue.
+func (e *expandedView) updateFollow() {
+    e.follow = e.live && e.viewport.AtBottom()
+}
+
+// openPrompt empieza a escribir una búsqueda, un filtro o una ruta.
+func (e *expandedView) openPrompt(prompt int, label, value string) tea.Cmd {
+    e.prompt = prompt
+    e.previous = value
+    e.input.Prompt = label
+    // En la ruta hay que dejar sitio a la pista de los colores.
+    e.input.Width = max(e.viewport.Width-lipgloss.Width(label)-1, 1)
+    if prompt == promptSave {
+        e.input.Width = max(e.input.Width-20, 1)
+    }
+    e.input.SetValue(value)
+    e.input.CursorEnd()
+    e.layoutViewport()
+    return e.input.Focus()
+}
+
+// closePrompt deja de escribir.
+func (e *expandedView) closePrompt() {
+    e.prompt = promptNone
+    e.input.Blur()
+    e.layoutViewport()
+}
+
+// updatePrompt atiende una tecla mientras se escribe. La búsqueda y el
+// filtro se aplican con cada tecla.
+func (e *expandedView) updatePrompt(msg tea.KeyMsg) tea.Cmd {
+    switch {
+    case key.Matches(msg, e.keys.Confirm):
+        prompt := e.prompt
+        e.closePrompt()
+        if prompt == promptSave {
+            return e.save(e.input.Value())
+        }
+        return nil
+
+    case key.Matches(msg, e.keys.Cancel):
+        switch e.prompt {
+        case promptSearch:
+            e.setQuery(e.previous)
+        case promptFilter:
+            e.setFilter(e.previous)
+        }
+        e.closePrompt()
+        return nil
+
+    case e.prompt == promptSave && key.Matches(msg, e.keys.ToggleColors):
+        e.colors = !e.colors
+        return nil
+    }
+
+    var cmd tea.Cmd
+    e.input, cmd = e.input.Update(msg)
+    switch e.prompt {
+    case promptSearch:
+        e.setQuery(e.input.Value())
+    case promptFilter:
+        e.setFilter(e.input.Value())
+    }
+    return cmd
+}
+
+// setQuery busca 'query' y salta a la primera coincidencia desde lo que se ve.
+func (e *expandedView) setQuery(query string) {
+    e.query = query
+    e.apply()
+    e.current = 0
+    for i, m := range e.matches {
+        if m.line >= e.viewport.YOffset {
+            e.current = i
+            break
+        }
+    }
+    e.render()
+    e.showCurrent()
+}
+
+// setFilter deja solo las líneas que contienen 'filter'.
+func (e *expandedView) setFilter(filter string) {
+    e.filter = filter
+    e.apply()
+    e.current = 0
+    e.render()
+    e.viewport.GotoTop()
+    e.updateFollow()
+}
+
+// step pasa a la coincidencia siguiente (1) o anterior (-1), dando la vuelta.
+func (e *expandedView) step(delta int) {
+    if len(e.matches) == 0 {
+        return
+    }
+    e.current = (e.current + delta + len(e.matches)) % len(e.matches)
+    e.render()
+    e.showCurrent()
+}
+
+// showCurrent desplaza la vista hasta la coincidencia seleccionada si no se ve.
+func (e *expandedView) showCurrent() {
+    if len(e.matches) == 0 {
+        return
+    }
+    line := e.matches[e.current].line
+    if line < e.viewport.YOffset || line >= e.viewport.YOffset+e.viewport.Height {
+        e.viewport.SetYOffset(line - e.viewport.Height/3)
+    }
+    e.updateFollow()
+}
+
+// apply filtra las líneas, busca las coincidencias y repinta.
+func (e *expandedView) apply() {
+    e.shown = e.shown[:0]
+    for _, line := range e.lines {
+        if e.filter == "" || len(find(ansi.Strip(line), e.filter)) > 0 {
+            e.shown = append(e.shown, line)
+        }
+    }
+
+    e.matches = e.matches[:0]
+    if e.query != "" {
+        for i, line := range e.shown {
+            for _, span := range find(ansi.Strip(line), e.query) {
+                e.matches = append(e.matches, match{line: i, start: span[0], end: span[1]})
+            }
+        }
+    }
+    if e.current >= len(e.matches) {
+        e.current = 0
+    }
+    e.render()
+}
+
+// render vuelca en el viewport las líneas que se ven, con las
+// coincidencias resaltadas.
+func (e *expandedView) render() {
+    highlight := e.styles.Warning.Reverse(true)
+    selected := e.styles.Primary.Reverse(true).Bold(true)
+
+    lines := make([]string, len(e.shown))
+    copy(lines, e.shown)
+    // De la última a la primera: cada cambio deja igual las columnas de las anteriores.
+    for i := len(e.matches) - 1; i >= 0; i-- {
+        m := e.matches[i]
+        style := highlight
+        if i == e.current {
+            style = selected
+        }
+        line := lines[m.line]
+        text := ansi.Strip(ansi.Cut(line, m.start, m.end))
+        lines[m.line] = ansi.Cut(line, 0, m.start) + style.Render(text) + ansi.Cut(line, m.end, ansi.StringWidth(line))
+    }
+    e.viewport.SetContent(strings.Join(lines, "\n"))
+    e.layoutViewport()
+}
+
+// layoutViewport deja una línea abajo para el prompt o el estado de la
+// búsqueda cuando hacen falta.
+func (e *expandedView) layoutViewport() {
+    e.viewport.Height = e.height
+    if e.statusLine() != "" && e.height > 1 {
+        e.viewport.Height--
+    }
+}
+
+// statusLine es la línea de abajo: el prompt o, si no, la búsqueda y el
+// filtro que hay puestos. "" si no hay nada que mostrar.
+func (e *expandedView) statusLine() string {
+    if e.typing() {
+        line := e.input.View()
+        if e.prompt == promptSave {
+            colors := "sin colores"
+            if e.colors {
+                colors = "con colores"
+            }
+            line += e.styles.Muted.Render(fmt.Sprintf("  (%s: %s)", e.keys.ToggleColors.Help().Key, colors))
+        }
+        return line
+    }
+
+    var parts []string
+    if e.query != "" {
+        found := "sin coincidencias"
+        if len(e.matches) > 0 {
+            found = fmt.Sprintf("%d/%d", e.current+1, len(e.matches))
+        }
+        parts = append(parts, fmt.Sprintf("/%s: %s", e.query, found))
+    }
+    if e.filter != "" {
+        parts = append(parts, fmt.Sprintf("filtro %q: %d de %d líneas", e.filter, len(e.shown), len(e.lines)))
+    }
+    if e.live {
+        follow := e.keys.Follow.Help().Key
+        if e.follow {
+            parts = append(parts, fmt.Sprintf("● siguiendo (%s: pausar)", follow))
+        } else {
+            parts = append(parts, fmt.Sprintf("⏸ en pausa (%s: seguir)", follow))
+        }
+    }
+    return e.styles.Muted.Render(strings.Join(parts, " · "))
+}
+
+// View compone la vista: el viewport y, si hace falta, la línea de abajo.
+func (e *expandedView) View() string {
+    status := e.statusLine()
+    if status == "" || e.height <= 1 {
+        return e.viewport.View()
+    }
+    return lipgloss.JoinVertical(lipgloss.Left, e.viewport.View(), ansi.Truncate(status, e.viewport.Width, "…"))
+}
+
+// text es lo que se ve (con el filtro aplicado), con o sin colores.
+func (e *expandedView) text(colors bool) string {
+    text := strings.Join(e.shown, "\n")
+    if !colors {
+        text = ansi.Strip(text)
+    }
+    return text + "\n"
+}
+
+// save guarda lo que se ve en 'path' y avisa del resultado.
+func (e *expandedView) save(path string) tea.Cmd {
+    text := e.text(e.colors)
+    return func() tea.Msg {
+        path := strings.TrimSpace(path)
+        if path == "" {
+            return block.NewNotification(block.SeverityError, "", "No se ha guardado: falta la ruta")
+        }
+        if rest, ok := strings.CutPrefix(path, "~/"); ok {
+            if home, err := os.UserHomeDir(); err == nil {
+                path = filepath.Join(home, rest)
+            }
+        }
+        if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+            return block.NewNotification(block.SeverityError, "", "Error guardando: %v", err)
+        }
+        if abs, err := filepath.Abs(path); err == nil {
+            path = abs
+        }
+        return block.NewNotification(block.SeveritySuccess, "", "Guardado en %s", path)
+    }
+}
+
+// clipboard manda lo que se ve, sin colores, al portapapeles de la terminal con
+// OSC 52 (funciona también por SSH; dentro de tmux o screen hay que
+// envolverlo). La terminal no confirma si lo acepta.
+func (e *expandedView) clipboard() tea.Cmd {
+    text, lines := e.text(false), len(e.shown)
+    return func() tea.Msg {
+        seq := osc52.New(text)
+        switch {
+        case os.Getenv("TMUX") != "":
+            seq = seq.Tmux()
+        case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+            seq = seq.Screen()
+        }
+        // Por la salida del programa: a la vez que el renderer, directamente
+        // a os.Stdout podría partir un fotograma.
+        if _, err := seq.WriteTo(terminal); err != nil {
+            return block.NewNotification(block.SeverityError, "", "Error copiando: %v", err)
+        }
+        return block.NewNotification(block.SeveritySuccess, "", "Copiadas %d líneas al portapapeles", lines)
+    }
+}
+
+// find devuelve las columnas (en celdas) de cada aparición de 'query' en
+// 'text'. Como en vim con smartcase, sin mayúsculas en 'query' no
+// distingue mayúsculas de minúsculas.
+func find(text, query string) [][2]int {
+    if query == "" {
+        return nil
+    }
+    haystack, needle := text, query
+    if !hasUpper(query) {
+        // Si pasar a minúsculas cambia la longitud, las posiciones no valdrían.
+        if lower := strings.ToLower(text); len(lower) == len(text) {
+            haystack, needle = lower, strings.ToLower(query)
+        }
+    }
+
+    var spans [][2]int
+    for offset := 0; ; {
+        i := strings.Index(haystack[offset:], needle)
+        if i < 0 {
+            return spans
+        }
+        start := offset + i
+        end := start + len(needle)
+        spans = append(spans, [2]int{ansi.StringWidth(text[:start]), ansi.StringWidth(text[:end])})
+        offset = end
+    }
+}
+
+// hasUpper indica si 's' tiene alguna mayúscula.
+func hasUpper(s string) bool {
+    for _, r := range s {
+        if unicode.IsUpper(r) {
+            return true
+        }
+    }
+    return false
+}
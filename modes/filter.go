@@ -2,6 +2,7 @@
 package modes
 
 import (
+    "bufio"
     "fmt"
     "log"
     "os"
@@ -11,18 +12,22 @@ import (
     //"github.com/urfave/cli/v2"
 
     // --- IMPORTS PARA EL MODELO DE BUBBLE TEA ---
+    "github.com/charmbracelet/bubbles/key"
     "github.com/charmbracelet/bubbles/textinput"    
     "github.com/charmbracelet/bubbles/viewport"
     "github.com/charmbracelet/bubbletea"
     "github.com/charmbracelet/lipgloss"
+    "github.com/urfave/cli/v2"
 
 
     // --- IMPORTS PARA LA LÓGICA DE LA APLICACIÓN ---
     "github.com/gas/fancy-welcome/config"
     "github.com/gas/fancy-welcome/shared"
     "github.com/gas/fancy-welcome/shared/block"
+    "github.com/gas/fancy-welcome/shared/data"
     "github.com/gas/fancy-welcome/themes"
     "github.com/gas/fancy-welcome/logging"
+    "github.com/gas/fancy-welcome/blocks/filter"
 
     //"github.com/gas/fancy-welcome/blocks/shell_command"
     //"github.com/gas/fancy-welcome/blocks/system_info"
@@ -33,21 +38,39 @@ import (
 )
 
 // FilterModel es el modelo de estado SOLO para el subcomando 'filter'.
+// No usa [pages]: los bloques que crea con 'a' no son de ninguna página, así
+// que todos van en el layout de [general].
 type FilterModel struct {
     blocks           []block.Block
     viewport         viewport.Model // <-- Un solo viewport para todo
     focusIndex       int
     width, height    int
     isCreatingFilter bool
+    filterParent     string // Bloque al que se le está creando el filtro
     textInput        textinput.Model
-    expandedBlock    block.Block 
+    expanded         *expandedView // nil si se ve el dashboard
+    notes            *shared.Notifications // Avisos: el toast y el panel de mensajes
 
     // --- CAMPOS DE CONFIGURACIÓN Y ESTILOS ---
     blockFactory      map[string]func() block.Block
     theme             *themes.Theme
+    config            *config.Config
     globalConfig      config.GeneralConfig
     normalBorderStyle lipgloss.Style
     focusBorderStyle  lipgloss.Style
+    styles            *themes.StyleSheet
+    statusBar         bool // Barra de estado abajo ([general] status_bar)
+    showHelp          bool // La ayuda de teclas ('?') tapa la vista
+    showLog           bool // Panel de mensajes abajo
+    logHeight         int  // Alto del panel de mensajes; 0 si no se ve o no cabe
+
+    // --- CAMBIOS DE LAYOUT PENDIENTES DE GUARDAR ---
+    newBlocks     []config.BlockDef // Bloques creados con 'a'
+    removedBlocks []string          // Bloques de la config borrados con 'd'
+
+    // --- RECARGA EN CALIENTE ---
+    setup    *shared.SetupResult
+    reloader *shared.Reloader // nil si no hay recarga en caliente
 }
 
 // NewFilterModel: El constructor se asegura de que el modelo se cree con todo lo necesario.
@@ -57,20 +80,13 @@ func NewFilterModel(setupResult *shared.SetupResult) FilterModel {
     ti.Placeholder = "Término a filtrar (ej: -i -v ERROR)"
     // Hemos creado el textinput. No lo enfocamos hasta que el usuario pulse 'a'
 
+    // Todos los estilos salen del tema.
+    styles := setupResult.Theme.StyleSheet()
+
     //El viewport se crea aquí una sola vez. Antes en RunTuiMode,
     vp := viewport.New(100, 20) // El tamaño se ajustará con el primer WindowSizeMsg
-    vp.Style = lipgloss.NewStyle().
-        Background(lipgloss.Color(setupResult.Theme.Colors.Background)).
-        Foreground(lipgloss.Color(setupResult.Theme.Colors.Text))
-
-    // Los estilos de borde también se definen aquí.
-    normalBorderStyle := lipgloss.NewStyle().
-        Border(lipgloss.RoundedBorder()).
-        BorderForeground(lipgloss.Color(setupResult.Theme.Colors.Border))
-
-    focusBorderStyle := lipgloss.NewStyle().
-        Border(lipgloss.RoundedBorder()).
-        BorderForeground(lipgloss.Color(setupResult.Theme.Colors.Primary))
+    vp.Style = styles.Base
+    vp.KeyMap = setupResult.Keys.ViewportKeys()
 
     return FilterModel{
         blocks:            setupResult.ActiveBlocks,
@@ -78,10 +94,15 @@ func NewFilterModel(setupResult *shared.SetupResult) FilterModel {
         textInput:         ti,
         blockFactory:      setupResult.BlockFactory,
         theme:             setupResult.Theme,
+        config:            setupResult.Config,
         globalConfig:      setupResult.Config.General,
         viewport:          vp,
-        normalBorderStyle: normalBorderStyle,
-        focusBorderStyle:  focusBorderStyle,
+        normalBorderStyle: styles.Border,
+        focusBorderStyle:  styles.FocusedBorder,
+        styles:            styles,
+        statusBar:         setupResult.Config.General.ShowStatusBar(),
+        notes:             shared.NewNotifications(),
+        setup:             setupResult,
     }
 }
 
@@ -89,8 +110,10 @@ func NewFilterModel(setupResult *shared.SetupResult) FilterModel {
 
 // Init inicializa el modo filtro.
 func (m FilterModel) Init() tea.Cmd {
-    // Puede que queramos un TriggerUpdateMsg inicial para los bloques
-    return func() tea.Msg { return block.TriggerUpdateMsg{} }
+    // Puede que queramos un TriggerUpdateMsg inicial para los bloques,
+    // y el reloj de los pies de bloque. Los problemas de la config, al
+    // panel de mensajes.
+    return tea.Batch(func() tea.Msg { return block.TriggerUpdateMsg{} }, shared.ClockTick(), m.notes.RecordSetup(m.setup))
 }
 
 // Update msgs para el modo filtro.
@@ -102,47 +125,64 @@ func (m FilterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
     // p := m.program // Mejor es no tenerlo en el modelo.
 
+    // Un cambio de config en disco se atiende en cualquier estado.
+    if changed, ok := msg.(shared.ConfigChangedMsg); ok {
+        return m.reload(changed.Path)
+    }
+    // El reloj solo fuerza un repintado (View vuelve a componer los pies).
+    if _, ok := msg.(shared.ClockTickMsg); ok {
+        return m, shared.ClockTick()
+    }
+    // Los avisos de abajo (guardados, copias, errores).
+    switch msg := msg.(type) {
+    case block.NotificationMsg:
+        return m, m.notes.Add(msg)
+    case shared.NotificationExpiredMsg:
+        m.notes.Expire(msg)
+        return m, nil
+    }
+
+    keys := m.setup.Keys
+
+    // ctrl+c sale siempre. La ayuda de teclas ('?') tapa la vista hasta
+    // la siguiente tecla; escribiendo (un filtro, una búsqueda...), '?' es texto.
+    switch msg := msg.(type) {
+    case tea.KeyMsg:
+        switch {
+        case msg.String() == "ctrl+c":
+            return m, tea.Quit
+        case m.showHelp:
+            m.showHelp = false
+            return m, nil
+        case !m.typing() && key.Matches(msg, keys.Help):
+            m.showHelp = true
+            return m, nil
+        case !m.typing() && key.Matches(msg, keys.Messages):
+            m.showLog = !m.showLog
+            m.resize()
+            return m, nil
+        }
+    case tea.MouseMsg:
+        if m.showHelp {
+            return m, nil
+        }
+    }
+
     // === MÁQUINA DE ESTADOS ===
 
     // --- ESTADO 1: VISTA EXPANDIDA (Máxima prioridad) ---
-    if m.expandedBlock != nil {
-        switch msg := msg.(type) {
-        case tea.KeyMsg:
-            switch msg.String() {
-            // Volver al dashboard
-            case "q", "esc", "enter":
-                m.expandedBlock = nil // <-- La clave: volvemos al estado dashboard
-                // Restauramos el contenido del dashboard en el viewport
-                m.viewport.SetContent(m.renderDashboardView()) // Usamos una función helper
-                return m, nil
-
-            // --- Guardar a archivo ---
-            case "s":
-                // Obtenemos el contenido expandido del bloque actual
-                content := m.expandedBlock.View() // Por defecto
-                if expander, ok := m.expandedBlock.(block.Expander); ok {
-                    content = expander.ExpandedView() // Usamos la vista expandida si existe 
-                }
-                
-                // Creamos un nombre de archivo único
-                fileName := fmt.Sprintf("%s_expanded_view.txt", m.expandedBlock.Name())
-                
-                // Escribimos a disco
-                err := os.WriteFile(fileName, []byte(content), 0644)
-                if err != nil {
-                    // En una app real, enviarías un tea.Msg de error para mostrarlo en la UI
-                    log.Printf("Error guardando archivo: %v", err)
-                }
-                // Podrías enviar un mensaje de confirmación también
-                return m, nil
-            }            
+    // Teclas y ratón son suyos; el resto de mensajes sigue llegando a los
+    // bloques para que la vista se mantenga al día.
+    if m.expanded != nil {
+        cmd, closed := m.expanded.Update(msg)
+        if closed {
+            m.expanded = nil // Volvemos al dashboard; View lo recompone
+        }
+        switch msg.(type) {
+        case tea.KeyMsg, tea.MouseMsg:
+            return m, cmd
         }
-        
-        // Si no es una tecla de control, pasamos el mensaje al viewport para el scroll
-        m.viewport, cmd = m.viewport.Update(msg)
         cmds = append(cmds, cmd)
-        return m, tea.Batch(cmds...) 
-        // return m, cmd
     }
 
 
@@ -150,42 +190,70 @@ func (m FilterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
     if m.isCreatingFilter {
         switch msg := msg.(type) {
         case tea.KeyMsg:
-            switch msg.Type {
+            switch {
             // Caso 1: El usuario pulsa Enter -> Creamos el bloque
-            case tea.KeyEnter: // o msg.String()
+            case key.Matches(msg, keys.Confirm):
+                // El padre se busca por nombre: una recarga puede haberlo
+                // movido de sitio.
+                parentIndex := blockIndex(m.blocks, m.filterParent)
+                if parentIndex < 0 {
+                    m.cancelFilter()
+                    return m, nil
+                }
                 filterQuery := m.textInput.Value() // 
-                parentBlock := m.blocks[m.focusIndex] // 
+                parentBlock := m.blocks[parentIndex] // 
                 parentName := parentBlock.Name()
 
+                // Un bloque Filter acepta consultas inválidas (enseña el error
+                // en su vista); aquí lo comprobamos antes para no crearlo.
+                filterOpts, err := filter.ParseQuery(filterQuery)
+                if err == nil {
+                    _, err = filter.NewMatcher(filterOpts)
+                }
+                if err != nil {
+                    return m, block.Notify(block.SeverityError, parentName, "Filtro no válido: %v", err)
+                }
+
                 // 3. Creamos la configuración para el nuevo bloque.
-                newBlockName := fmt.Sprintf("%s_filter_%d", parentName, len(m.blocks)+1) // 
+                newBlockName := m.newBlockName(parentName)
                 newBlockConfig := map[string]interface{}{
                     "name":       newBlockName,
                     "type":       "Filter",
                     "listens_to": parentName,
                     "filter":     filterQuery,
                 }
+                if position := parentBlock.Position(); position != "" {
+                    newBlockConfig["position"] = position
+                }
 
                 // 4. Creamos e inicializamos el nuevo bloque.
                 factory := m.blockFactory[newBlockConfig["type"].(string)] // 
                 newBlock := factory()
-                newBlock.Init(newBlockConfig, m.globalConfig, m.theme)
+                if err := newBlock.Init(newBlockConfig, m.globalConfig, m.theme.StyleSheet()); err != nil {
+                    // No se añade ni se guarda: el input sigue abierto para corregirlo.
+                    logging.Log.Printf("[%s] Error initializing filter block: %v", newBlockName, err)
+                    return m, block.Notify(block.SeverityError, newBlockName, "No se ha creado el filtro: %v", err)
+                }
+
+                // Lo apuntamos para poder guardarlo luego con 'w'.
+                m.newBlocks = append(m.newBlocks, config.BlockDef{
+                    Name:   newBlockName,
+                    Keys:   []string{"type", "listens_to", "filter", "position"},
+                    Values: newBlockConfig,
+                })
 
                 // 5. Lo insertamos en el slice justo después de su padre.
-                insertionIndex := m.focusIndex + 1 // 
+                insertionIndex := parentIndex + 1 // 
                 m.blocks = append(m.blocks[:insertionIndex], append([]block.Block{newBlock}, m.blocks[insertionIndex:]...)...) // 
+                m.resize() // El layout se recoloca con el bloque nuevo
 
                 // 6. Volvemos al modo normal y reseteamos el input.
-                m.isCreatingFilter = false
-                m.textInput.Reset() 
-                m.textInput.Blur() // ??
-                return m, nil
+                m.cancelFilter()
+                return m, block.Notify(block.SeverityInfo, "", "Bloque '%s' creado ('w' para guardar el layout)", newBlockName)
 
             // Caso 2: El usuario pulsa Escape -> Cancelamos
-            case tea.KeyEsc:
-                m.isCreatingFilter = false // 
-                m.textInput.Reset()      // 
-                m.textInput.Blur() // ??
+            case key.Matches(msg, keys.Cancel):
+                m.cancelFilter()
                 return m, nil
             }
         }
@@ -201,46 +269,136 @@ func (m FilterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
     case tea.WindowSizeMsg:
         m.width = msg.Width
         m.height = msg.Height
-        m.viewport.Width = msg.Width
-        m.viewport.Height = msg.Height
+        m.resize()
         return m, nil 
 
     case tea.KeyMsg:
-        switch msg.String() {
+        // El bloque con el foco ve las teclas antes que el dashboard.
+        if len(m.blocks) > 0 {
+            if handled, cmd := sendKey(m.blocks[m.focusIndex], msg); handled {
+                return m, cmd
+            }
+        }
+
+        switch {
         // El usuario pulsa 'a' para AÑADIR un filtro
-        case "a":
+        case key.Matches(msg, keys.AddFilter):
+            if len(m.blocks) == 0 {
+                return m, nil // No hay bloque del que colgar el filtro
+            }
             m.isCreatingFilter = true  // Cambiamos al modo de creación
+            m.filterParent = m.blocks[m.focusIndex].Name()
             m.textInput.Focus()        // 
             return m, textinput.Blink // 
 
-        case "q", "ctrl+c":
+        case key.Matches(msg, keys.Quit):
             return m, tea.Quit
 
-        case "enter":
-            focusedBlock := m.blocks[m.focusIndex]
-            m.expandedBlock = focusedBlock
-            content := focusedBlock.View()
+        // 'w' guarda en el TOML los bloques creados/borrados y el nuevo orden
+        case key.Matches(msg, keys.SaveLayout):
+            return m, m.saveLayout()
 
-            // El bloque enfocado tiene una vista expandida?
-            if expander, ok := focusedBlock.(block.Expander); ok {
-                content = expander.ExpandedView()
-            } 
-            m.viewport.SetContent(content)
-            m.viewport.GotoTop()
+        // 'd' borra el bloque enfocado, solo si es derivado (escucha a otro)
+        case key.Matches(msg, keys.Delete):
+            return m, m.removeFocusedBlock()
+
+        // 't' pasa al siguiente tema
+        case key.Matches(msg, keys.Theme):
+            if m.reloader == nil {
+                return m, nil
+            }
+            name, err := m.reloader.CycleTheme(m.setup, m.blocks)
+            if err != nil {
+                return m, block.Notify(block.SeverityError, "", "Error cambiando de tema: %v", err)
+            }
+            m.applyTheme()
+            return m, block.Notify(block.SeverityInfo, "", "Tema: %s", name)
+
+        case key.Matches(msg, keys.Expand):
+            if len(m.blocks) == 0 {
+                return m, nil
+            }
+            m.expanded = newExpandedView(m.blocks[m.focusIndex], keys, m.styles, true, m.viewport.Width, m.viewport.Height)
             return m, nil
 
-        case "tab":
-            m.focusIndex = (m.focusIndex + 1) % len(m.blocks)
-            m.viewport.SetContent(m.renderDashboardView()) // Refrescamos para que se vea el nuevo borde
+        case key.Matches(msg, keys.FocusNext):
+            m.setFocus(m.setup.Layout.NextVisible(m.width, m.blocks, m.focusIndex))
             return m, nil
 
-        case "up", "k", "down", "j", "pgup", "pgdown":
+        case key.Matches(msg, keys.FocusPrev):
+            m.setFocus(m.setup.Layout.PrevVisible(m.width, m.blocks, m.focusIndex))
+            return m, nil
+
+        case key.Matches(msg, keys.Up, keys.Down, keys.Left, keys.Right):
+            if next := m.setup.Layout.Neighbor(m.focusIndex, focusDirection(keys, msg)); next >= 0 {
+                m.setFocus(next)
+                return m, nil
+            }
+            // Sin bloque en esa dirección, arriba y abajo desplazan el dashboard.
+            m.refreshDashboard()
+            m.viewport, cmd = m.viewport.Update(msg)
+            cmds = append(cmds, cmd)
+
+        case key.Matches(msg, keys.ScrollDown, keys.ScrollUp):
+            // Desplazan el bloque con el foco si no le cabe todo; si no, el dashboard.
+            delta := 1
+            if key.Matches(msg, keys.ScrollUp) {
+                delta = -1
+            }
+            if len(m.blocks) > 0 && m.setup.Layout.ScrollBy(m.blocks[m.focusIndex].Name(), delta) {
+                return m, nil
+            }
+            m.refreshDashboard()
+            m.viewport, cmd = m.viewport.Update(msg)
+            cmds = append(cmds, cmd)
+
+        case key.Matches(msg, keys.PageUp, keys.PageDown):
+            m.refreshDashboard()
             m.viewport, cmd = m.viewport.Update(msg) // Pasa el mensaje al viewport principal
             cmds = append(cmds, cmd)
+
+        case key.Matches(msg, keys.Top):
+            m.viewport.GotoTop()
+
+        case key.Matches(msg, keys.Bottom):
+            m.refreshDashboard()
+            m.viewport.GotoBottom()
         }
+
+    case tea.MouseMsg:
+        // Un clic da el foco al bloque; la rueda desplaza el bloque que está
+        // debajo si no le cabe todo y, si no, el dashboard.
+        // Lo que cae debajo del viewport (panel de mensajes, barra) no cuenta.
+        if msg.Y >= m.viewport.Height {
+            return m, nil
+        }
+        press := msg.Action == tea.MouseActionPress
+        target := m.setup.Layout.BlockAt(msg.X, msg.Y+m.viewport.YOffset)
+        switch {
+        case press && msg.Button == tea.MouseButtonLeft:
+            if target >= 0 {
+                m.setFocus(target)
+            }
+            return m, nil
+        case press && target >= 0 && (msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown):
+            delta := m.viewport.MouseWheelDelta
+            if msg.Button == tea.MouseButtonWheelUp {
+                delta = -delta
+            }
+            if m.setup.Layout.ScrollBy(m.blocks[target].Name(), delta) {
+                return m, nil
+            }
+        }
+        m.refreshDashboard()
+        m.viewport, cmd = m.viewport.Update(msg)
+        cmds = append(cmds, cmd)
     
     
     default:
+        // Un tick sustituido por otro posterior ya no toca.
+        if tick, ok := msg.(block.BlockTickMsg); ok && tick.Stale() {
+            break
+        }
         // --- BUCLE DE DELEGACIÓN ---
         // ¿Es un mensaje dirigido a un bloque específico?
         if targetMsg, ok := msg.(block.TargetedMsg); ok {
@@ -263,6 +421,12 @@ func (m FilterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
                 cmds = append(cmds, blockCmd)
             }           
         }
+        // La vista expandida enseña lo último del bloque.
+        if m.expanded != nil {
+            m.expanded.refresh()
+        }
+        // Un bloque que empieza a fallar (o se recupera) se avisa abajo.
+        cmds = append(cmds, m.notes.Watch(m.blocks))
     }
 
     return m, tea.Batch(cmds...)
@@ -270,14 +434,28 @@ func (m FilterModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // View renderiza la UI del modo filtro.
 func (m FilterModel) View() string {
-    // Si estamos expandidos, el viewport ya tiene el contenido correcto.
-    if m.expandedBlock != nil {
-        return m.viewport.View()
+    keys := m.setup.Keys
+
+    // La ayuda de teclas va en lugar del dashboard o de la vista expandida.
+    if m.showHelp {
+        groups := keys.DashboardHelp(false, true)
+        if m.expanded != nil {
+            groups = keys.ExpandedHelp(true, m.expanded.live)
+        }
+        return m.withStatusBar(m.withLog(shared.RenderHelp(m.viewport.Width, m.viewport.Height, m.styles, groups)), "")
+    }
+
+    if m.expanded != nil {
+        hints := shared.KeyHints(keys.Back, keys.Search, keys.FilterLines, keys.Save, keys.Copy, keys.Help)
+        if m.expanded.typing() {
+            hints = shared.KeyHints(keys.InputHelp()...)
+        }
+        return m.withStatusBar(m.withLog(m.expanded.View()), hints)
     }
 
     // Obtenemos el contenido del dashboard llamando a la función compartida.
-    dashboardContent := shared.RenderDashboard(
-        m.width, 
+    dashboardContent := m.setup.Layout.Render(
+        m.width,
         m.blocks, 
         m.focusIndex, 
         m.normalBorderStyle, 
@@ -291,11 +469,12 @@ func (m FilterModel) View() string {
     // tb para el modo inline interactivo como ctrl+R en fzf...
 
     // Y si estamos creando un filtro, le añadimos el input.
-    if m.isCreatingFilter {
+    if m.isCreatingFilter && len(m.blocks) > 0 {
         //dashboardContent := m.renderDashboardView()
         inputView := fmt.Sprintf(
-            "\n\nAñadir filtro para '%s' (Enter para aceptar, Esc para cancelar):\n%s",
-            m.blocks[m.focusIndex].Name(),
+            "\n\nAñadir filtro para '%s' (%s):\n%s",
+            m.filterParent,
+            shared.KeyHints(keys.InputHelp()...),
             m.textInput.View(),
         )
         // Unimos el dashboard (sin el viewport) con el input.
@@ -306,101 +485,320 @@ func (m FilterModel) View() string {
     // Si no, simplemente mostramos el dashboard a través del viewport.
     //m.viewport.SetContent(dashboardContent)
     //return m.viewport.View()
-    return mainView
+    return m.withStatusBar(m.withLog(mainView), shared.KeyHints(keys.FocusNext, keys.Expand, keys.AddFilter, keys.Delete, keys.SaveLayout, keys.Messages, keys.Help, keys.Quit))
 }
 
-// --- HELPER: renderDashboardView ---
-// para componer los bloques en un solo string.
-func (m FilterModel) renderDashboardView() string {
-
-    if m.expandedBlock != nil {
-        // Si estamos en vista expandida, mostramos ESE viewport
-        return m.viewport.View()
+// withLog añade el panel de mensajes debajo de la vista, si se ve, y el
+// toast sobre la última línea.
+func (m FilterModel) withLog(view string) string {
+    if m.logHeight > 0 {
+        view = lipgloss.JoinVertical(lipgloss.Left, view, m.notes.RenderLog(m.width, m.logHeight, m.styles))
     }
-    
-    if m.width == 0 { return "Initializing..." }
+    return m.notes.Overlay(view, m.width, m.styles)
+}
 
-    var finalLayout []string       // Almacenará los elementos finales (columnas unidas y bloques full)
-    var leftColumnViews []string   // Vistas pendientes para la columna izquierda
-    var rightColumnViews []string  // Vistas pendientes para la columna derecha
+// withStatusBar añade la barra de estado debajo de la vista, si está activa.
+func (m FilterModel) withStatusBar(view, keys string) string {
+    if !m.statusBar || m.width == 0 {
+        return view
+    }
+    bar := shared.RenderStatusBar(m.width, m.styles, keys, m.blocks, m.focusIndex)
+    return lipgloss.JoinVertical(lipgloss.Left, view, bar)
+}
 
-    // Función helper para procesar y limpiar las columnas pendientes.
-    processPendingColumns := func() {
-        if len(leftColumnViews) > 0 || len(rightColumnViews) > 0 {
-            leftColumn := lipgloss.JoinVertical(lipgloss.Left, leftColumnViews...)
-            rightColumn := lipgloss.JoinVertical(lipgloss.Left, rightColumnViews...)
-            
-            // Une las columnas horizontalmente
-            joinedCols := lipgloss.JoinHorizontal(lipgloss.Top, leftColumn, rightColumn)
-            finalLayout = append(finalLayout, joinedCols)
+// --- HELPERS DE PERSISTENCIA DEL LAYOUT ---
 
-            // Limpia los slices para la siguiente sección de columnas
-            leftColumnViews = []string{}
-            rightColumnViews = []string{}
+// newBlockName genera un nombre '<padre>_filter_N' que no exista todavía.
+func (m FilterModel) newBlockName(parentName string) string {
+    for n := len(m.blocks) + 1; ; n++ {
+        name := fmt.Sprintf("%s_filter_%d", parentName, n)
+        if _, exists := m.config.Blocks[name]; exists {
+            continue
+        }
+        if m.findBlock(name) >= 0 {
+            continue
         }
+        return name
     }
+}
 
-    // 1. Renderizar y agrupar vistas por posición
+func (m FilterModel) findBlock(name string) int {
     for i, b := range m.blocks {
-        blockView := b.View()
-        var renderedBlock string // Declaramos la variable que contendrá el resultado final
-        //logging.Log.Printf("FOCUS CHECK: focusIndex=%d, blockIndex=%d, blockName=%s", m.focusIndex, i, b.Name())
+        if b.Name() == name {
+            return i
+        }
+    }
+    return -1
+}
+
+// isDerived indica si un bloque escucha a otro (Filter, WordCounter...).
+func (m FilterModel) isDerived(name string) bool {
+    for _, def := range m.newBlocks {
+        if def.Name == name {
+            return true
+        }
+    }
+    blockConfig, _ := m.config.Blocks[name].(map[string]interface{})
+    _, ok := blockConfig["listens_to"]
+    return ok
+}
 
-        // --- LÓGICA DE RENDERIZADO CONDICIONAL ---
-        if b.RendererName() == "preformatted_text" {
-            // Si es pre-formateado, usamos la vista cruda, sin bordes ni estilos.
-            renderedBlock = blockView
-            logging.Log.Printf("PREFORMATTED: blockIndex=%d, blockName=%s", i, b.Name())
+// removeFocusedBlock quita del dashboard el bloque enfocado si es derivado.
+// Si aún no se había guardado, basta con olvidarlo; si no, se borrará del
+// archivo en el próximo guardado.
+func (m *FilterModel) removeFocusedBlock() tea.Cmd {
+    if len(m.blocks) == 0 {
+        return nil
+    }
+    name := m.blocks[m.focusIndex].Name()
+    if !m.isDerived(name) {
+        return block.Notify(block.SeverityWarning, "", "'%s' no es un bloque derivado, no se puede borrar", name)
+    }
 
-        } else {
-            // Para todos los demás, aplicamos el borde y el estilo de foco.
-            var borderStyle lipgloss.Style
-            if i == m.focusIndex {
-                borderStyle = m.focusBorderStyle
-            } else {
-                borderStyle = m.normalBorderStyle
-            }
+    pending := false
+    for i, def := range m.newBlocks {
+        if def.Name == name {
+            m.newBlocks = append(m.newBlocks[:i], m.newBlocks[i+1:]...)
+            pending = true
+            break
+        }
+    }
+    if !pending {
+        m.removedBlocks = append(m.removedBlocks, name)
+    }
+
+    m.blocks = append(m.blocks[:m.focusIndex], m.blocks[m.focusIndex+1:]...)
+    if m.focusIndex >= len(m.blocks) && m.focusIndex > 0 {
+        m.focusIndex--
+    }
+    m.resize()
+    return block.Notify(block.SeverityInfo, "", "Bloque '%s' borrado ('w' para guardar el layout)", name)
+}
+
+// layoutOrder calcula el nuevo 'enabled_blocks_order': el original sin los
+// bloques borrados y con cada bloque nuevo justo detrás del que le precede
+// en el dashboard. Así no se pierden los bloques que no están activos en
+// este modo (run_mode = "tty", errores de init...).
+func (m FilterModel) layoutOrder() []string {
+    removed := make(map[string]bool, len(m.removedBlocks))
+    for _, name := range m.removedBlocks {
+        removed[name] = true
+    }
 
-            position := b.Position() 
-            if position == "left" || position == "right" {
-                blockWidth := (m.width / 2) - 4 
-                renderedBlock = borderStyle.Width(blockWidth).Render(blockView)
-            } else {
-                blockWidth := m.width - 2
-                renderedBlock = borderStyle.Width(blockWidth).Render(blockView)
+    var order []string
+    for _, name := range m.config.General.EnabledBlocksOrder {
+        if !removed[name] {
+            order = append(order, name)
+        }
+    }
+
+    indexOf := func(name string) int {
+        for i, n := range order {
+            if n == name {
+                return i
             }
         }
+        return -1
+    }
+
+    for i, b := range m.blocks {
+        if indexOf(b.Name()) >= 0 {
+            continue
+        }
+        at := 0
+        if i > 0 {
+            at = indexOf(m.blocks[i-1].Name()) + 1
+        }
+        order = append(order[:at], append([]string{b.Name()}, order[at:]...)...)
+    }
+    return order
+}
+
+// saveLayout escribe los cambios pendientes en el archivo de configuración.
+func (m *FilterModel) saveLayout() tea.Cmd {
+    if len(m.newBlocks) == 0 && len(m.removedBlocks) == 0 {
+        return block.Notify(block.SeverityInfo, "", "No hay cambios que guardar")
+    }
+
+    order := m.layoutOrder()
+    path, err := m.config.SaveLayout(order, m.newBlocks, m.removedBlocks)
+    if err != nil {
+        logging.Log.Printf("Error saving layout: %v", err)
+        return block.Notify(block.SeverityError, "", "Error guardando el layout: %v", err)
+    }
+
+    // Reflejamos el guardado en la config en memoria.
+    for _, name := range m.removedBlocks {
+        delete(m.config.Blocks, name)
+    }
+    if m.config.Blocks == nil {
+        m.config.Blocks = make(map[string]interface{})
+    }
+    for _, def := range m.newBlocks {
+        m.config.Blocks[def.Name] = def.Values
+    }
+    m.config.General.EnabledBlocksOrder = order
+    m.newBlocks = nil
+    m.removedBlocks = nil
+    return block.Notify(block.SeveritySuccess, "", "Layout guardado en %s", path)
+}
+
+// --- HELPERS DE RECARGA EN CALIENTE ---
+
+// reload vuelve a cargar la config y el tema tras un cambio en disco. Los
+// bloques creados con 'a' y aún sin guardar se conservan, y los borrados
+// con 'd' siguen fuera. Si la config nueva no se puede cargar, se sigue
+// con la anterior.
+func (m FilterModel) reload(path string) (tea.Model, tea.Cmd) {
+    if m.reloader == nil {
+        return m, nil
+    }
+
+    pending := make(map[string]bool, len(m.newBlocks))
+    for _, def := range m.newBlocks {
+        pending[def.Name] = true
+    }
+    var current []block.Block
+    for _, b := range m.blocks {
+        if !pending[b.Name()] {
+            current = append(current, b)
+        }
+    }
+
+    result, cmd, err := m.reloader.Reload(m.setup, current)
+    if err != nil {
+        logging.Log.Printf("Error reloading config after change in %s: %v", path, err)
+        return m, block.Notify(block.SeverityError, "", "Error recargando la configuración: %v", err)
+    }
+
+    removed := make(map[string]bool, len(m.removedBlocks))
+    for _, name := range m.removedBlocks {
+        removed[name] = true
+    }
+    var blocks, dropped []block.Block
+    for _, b := range result.ActiveBlocks {
+        if removed[b.Name()] {
+            dropped = append(dropped, b)
+            continue
+        }
+        blocks = append(blocks, b)
+    }
+    shared.CloseBlocks(dropped)
 
-        // El resto de la lógica para añadir a las columnas se mantiene igual,
-        // pero ahora usa la variable 'renderedBlock'.
-        position := b.Position()
-        if position == "left" || position == "right" {
-             if position == "left" {
-                leftColumnViews = append(leftColumnViews, renderedBlock)
-            } else {
-                rightColumnViews = append(rightColumnViews, renderedBlock)
+    // Cada bloque pendiente vuelve detrás del que tenía delante.
+    for i, b := range m.blocks {
+        if !pending[b.Name()] {
+            continue
+        }
+        at := len(blocks)
+        if i > 0 {
+            for j, other := range blocks {
+                if other.Name() == m.blocks[i-1].Name() {
+                    at = j + 1
+                    break
+                }
             }
-        } else {
-             processPendingColumns()
-             finalLayout = append(finalLayout, renderedBlock)
         }
+        blocks = append(blocks[:at], append([]block.Block{b}, blocks[at:]...)...)
+    }
 
+    m.setup = result
+    m.blocks = blocks
+    m.config = result.Config
+    m.globalConfig = result.Config.General
+    m.statusBar = result.Config.General.ShowStatusBar()
+    m.viewport.KeyMap = result.Keys.ViewportKeys()
+    m.resize()
+    m.blockFactory = result.BlockFactory
+    if m.focusIndex >= len(m.blocks) {
+        m.focusIndex = 0
+    }
+    if m.expanded != nil && !containsBlock(m.blocks, m.expanded.block) {
+        m.expanded = nil
+    }
+    if m.isCreatingFilter && blockIndex(m.blocks, m.filterParent) < 0 {
+        m.cancelFilter() // El bloque al que iba el filtro ya no está
     }
+    m.applyTheme()
+    notice := m.notes.RecordSetup(result)
+    if notice == nil {
+        notice = block.Notify(block.SeverityInfo, "", "Configuración recargada")
+    }
+    return m, tea.Batch(cmd, notice)
+}
 
-    // 3. Procesa cualquier columna que haya quedado al final del bucle.
-    processPendingColumns()
+// cancelFilter sale de la creación de un filtro y vacía el input.
+func (m *FilterModel) cancelFilter() {
+    m.isCreatingFilter = false
+    m.filterParent = ""
+    m.textInput.Reset()
+    m.textInput.Blur()
+}
 
-    // 1. Unimos todos los elementos del layout final en un solo string.
-    fullLayout := lipgloss.JoinVertical(lipgloss.Left, finalLayout...)
+// blockIndex devuelve la posición del bloque 'name', o -1 si no está.
+func blockIndex(blocks []block.Block, name string) int {
+    for i, b := range blocks {
+        if b.Name() == name {
+            return i
+        }
+    }
+    return -1
+}
 
-    // 2. Establecemos ese string como el contenido de nuestro viewport.
-    m.viewport.SetContent(fullLayout)
+// resize ajusta el viewport a la ventana, dejando sitio a la barra de
+// estado y al panel de mensajes.
+func (m *FilterModel) resize() {
+    m.viewport.Width = m.width
+    m.viewport.Height = m.height
+    if m.statusBar && m.viewport.Height > 1 {
+        m.viewport.Height--
+    }
+    m.logHeight = 0
+    if m.showLog && m.viewport.Height > 2*shared.MessageLogHeight {
+        m.logHeight = shared.MessageLogHeight
+        m.viewport.Height -= m.logHeight
+    }
+    // Los breakpoints y el ancho de cada bloque dependen del de la ventana.
+    m.setup.Layout.Resize(m.width, m.blocks, m.normalBorderStyle)
+    if m.expanded != nil {
+        m.expanded.setSize(m.viewport.Width, m.viewport.Height)
+    }
+}
 
-    // 3. Devolvemos la vista del viewport, que se encargará del scroll.
-    return m.viewport.View()
+// refreshDashboard vuelca el dashboard en el viewport para que pueda
+// desplazarse sobre el contenido actual (View lo vuelve a componer).
+func (m *FilterModel) refreshDashboard() {
+    m.viewport.SetContent(m.setup.Layout.Render(m.width, m.blocks, m.focusIndex, m.normalBorderStyle, m.focusBorderStyle))
 }
 
+// setFocus pasa el foco al bloque 'index' y desplaza el dashboard para
+// que se vea.
+func (m *FilterModel) setFocus(index int) {
+    m.focusIndex = index
+    m.refreshDashboard()
+    if region, ok := m.setup.Layout.RegionOf(index); ok {
+        scrollIntoView(&m.viewport, region)
+    }
+}
 
+// applyTheme aplica el tema actual a los estilos propios del modelo.
+func (m *FilterModel) applyTheme() {
+    m.theme = m.setup.Theme
+    styles := m.theme.StyleSheet()
+    m.styles = styles
+    m.viewport.Style = styles.Base
+    m.normalBorderStyle = styles.Border
+    m.focusBorderStyle = styles.FocusedBorder
+    if m.expanded != nil {
+        m.expanded.setStyles(m.setup.Keys, styles)
+    }
+}
+
+// typing indica si se está escribiendo (un filtro o en la vista expandida):
+// las teclas son texto.
+func (m FilterModel) typing() bool {
+    return m.isCreatingFilter || (m.expanded != nil && m.expanded.typing())
+}
 
 // --- RUNNERS: implementamos el TUI runner correctamente ---
 
@@ -408,14 +806,23 @@ func (m FilterModel) renderDashboardView() string {
 func RunFilterTUI() error {
     fmt.Println("Modo TUI para 'fancy filter'")
 
-    setupResult, err := shared.Setup("") // Llama a la función centralizada
+    setupResult, err := shared.Setup("", shared.RunModeTUI) // Llama a la función centralizada
     if err != nil { 
         return fmt.Errorf("error al inicializar la configuración: %w", err)
     }
 
     // Creamos el modelo usando el constructor.    
+    // Recarga en caliente: la config y los temas se vigilan mientras dura la TUI.
+    reloader := shared.NewReloader()
+    defer reloader.Close()
+
     initialModel := NewFilterModel(setupResult) // Pasa el resultado al constructor
-    p := tea.NewProgram(initialModel, tea.WithAltScreen(), tea.WithMouseAllMotion()) //?? util + o - que withMouseCellMotion?
+    initialModel.reloader = reloader
+    p := tea.NewProgram(initialModel, tea.WithAltScreen(), tea.WithMouseAllMotion(), tea.WithOutput(terminal)) //?? util + o - que withMouseCellMotion?
+    reloader.SetProgram(p)
+    if err := reloader.Watch(setupResult.Config); err != nil {
+        logging.Log.Printf("Config hot-reload disabled: %v", err)
+    }
  
     // Antes de ejecutar el programa, iteramos sobre los bloques iniciales del modelo.
     for _, b := range initialModel.blocks {
@@ -426,17 +833,146 @@ func RunFilterTUI() error {
         }
     }
 
-    if _, err := p.Run(); err != nil {
+    finalModel, err := p.Run()
+    // Tras una recarga los bloques en marcha ya no son los iniciales.
+    if final, ok := finalModel.(FilterModel); ok {
+        shared.CloseBlocks(final.blocks)
+    } else {
+        shared.CloseBlocks(initialModel.blocks)
+    }
+    if err != nil {
         // Usamos log.Printf para que no cierre la aplicación con Fatalf y se vea el error TUI.
         log.Printf("Error al ejecutar el programa TUI: %v", err)
         return err
     }
 
+    shared.ReportProblems(os.Stderr, setupResult.Problems)
     return nil
 }
 
-// RunFilterTTY ejecuta la lógica de volcado para 'filter'.
-func RunFilterTTY() error {
-    fmt.Println("TODO: Implementar modo TTY para 'filter'")
+// FilterTTYOptions son los parámetros de 'filter --simple'.
+type FilterTTYOptions struct {
+    Source      string   // Bloque de la config que produce la entrada; vacío o "-" para stdin.
+    Expressions []string // Filtros al estilo grep, aplicados en cadena.
+    Blocks      []string // Bloques Filter de la config cuyos filtros se reutilizan.
+    Highlight   bool     // Resalta las coincidencias.
+    Count       bool     // Solo imprime el número de coincidencias.
+}
+
+// RunFilterTTY ejecuta la lógica de volcado para 'filter': lee la salida de
+// un bloque de la config (o stdin), le aplica los filtros en cadena, como
+// 'grep a | grep b', e imprime el resultado en stdout.
+func RunFilterTTY(opts FilterTTYOptions) error {
+    expressions := opts.Expressions
+    source := opts.Source
+
+    // Solo cargamos la configuración si hace falta: filtrar stdin con
+    // expresiones sueltas tiene que funcionar sin ella.
+    var setupResult *shared.SetupResult
+    if len(opts.Blocks) > 0 || (source != "" && source != "-") {
+        var err error
+        setupResult, err = shared.Setup("", shared.RunModeTTY)
+        if err != nil {
+            return fmt.Errorf("error al inicializar la configuración: %w", err)
+        }
+    }
+
+    // Los bloques Filter aportan su filtro y, si no se indicó, su fuente.
+    for _, name := range opts.Blocks {
+        blockConfig, _ := setupResult.Config.Blocks[name].(map[string]interface{})
+        if blockType, _ := blockConfig["type"].(string); blockType != "Filter" {
+            return fmt.Errorf("'%s' no es un bloque de tipo Filter", name)
+        }
+        query, _ := blockConfig["filter"].(string)
+        expressions = append(expressions, query)
+        if source == "" {
+            source, _ = blockConfig["listens_to"].(string)
+        }
+    }
+
+    if len(expressions) == 0 {
+        return fmt.Errorf("no hay ningún filtro: usa --expr o --block")
+    }
+
+    var pipeline []*filter.Matcher
+    for _, expr := range expressions {
+        filterOpts, err := filter.ParseQuery(expr)
+        if err != nil {
+            return fmt.Errorf("filtro '%s': %w", expr, err)
+        }
+        matcher, err := filter.NewMatcher(filterOpts)
+        if err != nil {
+            return fmt.Errorf("filtro '%s': %w", expr, err)
+        }
+        pipeline = append(pipeline, matcher)
+    }
+    last := pipeline[len(pipeline)-1]
+
+    highlightStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
+    mark := func(s string) string { return highlightStyle.Render(s) }
+    emit := func(lines []string) {
+        for _, matcher := range pipeline {
+            lines = matcher.Feed(lines)
+        }
+        if opts.Count {
+            return
+        }
+        for _, line := range lines {
+            if opts.Highlight {
+                line = last.Highlight(line, mark)
+            }
+            fmt.Println(line)
+        }
+    }
+
+    if source == "" || source == "-" {
+        if err := filterStdin(emit); err != nil {
+            return err
+        }
+    } else {
+        if err := filterBlock(setupResult, source, emit); err != nil {
+            return err
+        }
+    }
+
+    if opts.Count {
+        fmt.Println(last.Matches())
+    }
+    // Como grep: código de salida 1 si no hubo coincidencias.
+    if last.Matches() == 0 {
+        return cli.Exit("", 1)
+    }
+    return nil
+}
+
+// filterStdin lee stdin línea a línea y las filtra según llegan.
+func filterStdin(emit func([]string)) error {
+    scanner := bufio.NewScanner(os.Stdin)
+    scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+    for scanner.Scan() {
+        emit([]string{scanner.Text()})
+    }
+    return scanner.Err()
+}
+
+// filterBlock ejecuta el bloque 'source' con el Driver y filtra su salida.
+// Los lotes de un stream se filtran según llegan, hasta el timeout del bloque.
+func filterBlock(setupResult *shared.SetupResult, source string, emit func([]string)) error {
+    src, err := setupResult.NewBlock(source)
+    if err != nil {
+        return err
+    }
+    blocks := []block.Block{src}
+    defer shared.CloseBlocks(blocks)
+
+    driver := shared.NewDriver(blocks)
+    driver.OnMsg = func(msg tea.Msg) {
+        tee, ok := msg.(block.TeeOutputMsg)
+        if !ok || tee.SourceBlockID != source {
+            return
+        }
+        emit(data.AsLines(tee.Output))
+    }
+    driver.Run(setupResult.BlockTimeout)
     return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,46 @@
+// modes/focus.go
+package modes
+
+import (
+    "github.com/charmbracelet/bubbles/key"
+    "github.com/charmbracelet/bubbles/viewport"
+    "github.com/charmbracelet/bubbletea"
+
+    "github.com/gas/fancy-welcome/shared"
+    "github.com/gas/fancy-welcome/shared/block"
+)
+
+// focusDirection devuelve hacia dónde mueve el foco una de las teclas de
+// flecha del KeyMap (Up, Down, Left o Right).
+func focusDirection(keys *shared.KeyMap, msg tea.KeyMsg) int {
+    switch {
+    case key.Matches(msg, keys.Up):
+        return shared.Up
+    case key.Matches(msg, keys.Down):
+        return shared.Down
+    case key.Matches(msg, keys.Left):
+        return shared.Left
+    default:
+        return shared.Right
+    }
+}
+
+// sendKey pasa una tecla al bloque con el foco si tiene controles propios
+// (block.Interactive). Devuelve si la ha usado.
+func sendKey(focused block.Block, msg tea.KeyMsg) (bool, tea.Cmd) {
+    if interactive, ok := focused.(block.Interactive); ok {
+        return interactive.HandleKey(msg)
+    }
+    return false, nil
+}
+
+// scrollIntoView desplaza el viewport lo justo para que se vea la región
+// 'r' del dashboard (o su principio, si es más alta que el viewport).
+func scrollIntoView(vp *viewport.Model, r shared.Region) {
+    switch {
+    case r.Y < vp.YOffset || r.Height > vp.Height:
+        vp.SetYOffset(r.Y)
+    case r.Y+r.Height > vp.YOffset+vp.Height:
+        vp.SetYOffset(r.Y + r.Height - vp.Height)
+    }
+}
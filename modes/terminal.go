@@ -0,0 +1,30 @@
+// modes/terminal.go
+package modes
+
+import (
+    "os"
+    "sync"
+)
+
+// terminal es la salida de las TUIs: os.Stdout con un cerrojo en cada
+// escritura. El renderer de Bubble Tea escribe cada fotograma de una vez,
+// así lo que se escribe fuera de él (la secuencia OSC 52 del portapapeles)
+// no puede caer en medio de uno.
+var terminal = &lockedOutput{File: os.Stdout}
+
+// lockedOutput sigue siendo un *os.File para Bubble Tea, que así detecta
+// la terminal y su tamaño.
+type lockedOutput struct {
+    *os.File
+    mu sync.Mutex
+}
+
+func (o *lockedOutput) Write(p []byte) (int, error) {
+    o.mu.Lock()
+    defer o.mu.Unlock()
+    return o.File.Write(p)
+}
+
+func (o *lockedOutput) WriteString(s string) (int, error) {
+    return o.Write([]byte(s))
+}
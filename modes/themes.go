@@ -0,0 +1,60 @@
+// modes/themes.go
+package modes
+
+import (
+    "fmt"
+    "os"
+    "text/tabwriter"
+
+    "github.com/charmbracelet/lipgloss"
+    "github.com/urfave/cli/v2"
+
+    "github.com/gas/fancy-welcome/config"
+    "github.com/gas/fancy-welcome/themes"
+)
+
+// RunThemesList muestra los temas disponibles y de dónde sale cada uno.
+// El tema seleccionado en la configuración se marca con '*'.
+func RunThemesList() error {
+    infos, err := themes.List()
+    if err != nil {
+        return err
+    }
+
+    selected := themes.DefaultTheme
+    if cfg, err := config.LoadConfig(); err == nil && cfg.Theme.SelectedTheme != "" {
+        selected = cfg.Theme.SelectedTheme
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    for _, info := range infos {
+        mark := " "
+        if info.ID == selected {
+            mark = "*"
+        }
+        fmt.Fprintf(w, "%s %s\t%s\t%s\n", mark, info.ID, info.Name, info.Source)
+    }
+    return w.Flush()
+}
+
+// RunThemesShow muestra la paleta de un tema con una muestra de cada color.
+func RunThemesShow(themeName string) error {
+    theme, err := themes.LoadTheme(themeName)
+    if err != nil {
+        return cli.Exit(err.Error(), 1)
+    }
+    _, source, _ := themes.Read(themeName)
+
+    fmt.Printf("%s  %s\n\n", theme.Name, source)
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    for _, role := range theme.Colors.Roles() {
+        swatch := "    "
+        if role.Value == "" {
+            fmt.Fprintf(w, "  %s\t%s\t(sin definir)\n", swatch, role.Key)
+            continue
+        }
+        swatch = lipgloss.NewStyle().Background(lipgloss.Color(role.Value)).Render(swatch)
+        fmt.Fprintf(w, "  %s\t%s\t%s\n", swatch, role.Key, role.Value)
+    }
+    return w.Flush()
+}
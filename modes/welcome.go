@@ -3,82 +3,586 @@ package modes
 
 import (
     "fmt"
-    //"log"
-    //"os"
-    //"flag"
-    //"path/filepath"
+    "log"
+    "os"
+    "strconv"
 
-    //"github.com/charmbracelet/bubbles/textinput"    
+    "github.com/charmbracelet/bubbles/key"
     "github.com/charmbracelet/bubbles/viewport"
     "github.com/charmbracelet/bubbletea"
-    //"github.com/charmbracelet/lipgloss"
-    //"github.com/mattn/go-isatty"
-    //"github.com/urfave/cli/v2"
+    "github.com/charmbracelet/lipgloss"
+    "github.com/charmbracelet/x/term"
 
-    //"github.com/gas/fancy-welcome/config"
-    //"github.com/gas/fancy-welcome/logging"
+    "github.com/gas/fancy-welcome/logging"
+    "github.com/gas/fancy-welcome/shared"
     "github.com/gas/fancy-welcome/shared/block"
-    //"github.com/gas/fancy-welcome/shared/layout"
-    //"github.com/gas/fancy-welcome/themes"
-    //"github.com/gas/fancy-welcome/blocks/shell_command"
-    //"github.com/gas/fancy-welcome/blocks/system_info"
-    //"github.com/gas/fancy-welcome/blocks/word_counter"
-    //"github.com/gas/fancy-welcome/blocks/filter"
-
-    //"github.com/gas/fancy-welcome/modes" 
+    "github.com/gas/fancy-welcome/themes"
 )
 
 // WelcomeModel es el modelo de estado SOLO para el subcomando 'welcome'.
+// Es un dashboard de solo lectura: foco, scroll y vista expandida,
+// sin el estado de input que necesita el modo filtro.
 type WelcomeModel struct {
-    blocks      []block.Block
-    width       int
-    height      int
-    focusIndex  int
-    viewport    viewport.Model
-    // ...estilos de borde, etc.
-    // ¡NOTA: No hay 'textInput' ni 'mode' string aquí!
+    blocks        []block.Block
+    width         int
+    height        int
+    focusIndex    int
+    viewport      viewport.Model
+    expanded      *expandedView // nil si se ve el dashboard
+    notes         *shared.Notifications // Avisos: el toast y el panel de mensajes
+
+    normalBorderStyle lipgloss.Style
+    focusBorderStyle  lipgloss.Style
+    styles            *themes.StyleSheet
+    statusBar         bool // Barra de estado abajo ([general] status_bar)
+    page              int  // Página de [pages] que se ve
+    showHelp          bool // La ayuda de teclas ('?') tapa el dashboard
+    showLog           bool // Panel de mensajes abajo
+    logHeight         int  // Alto del panel de mensajes; 0 si no se ve o no cabe
+
+    setup    *shared.SetupResult
+    reloader *shared.Reloader // nil si no hay recarga en caliente
+}
+
+// NewWelcomeModel construye el modelo a partir del resultado de shared.Setup.
+func NewWelcomeModel(setupResult *shared.SetupResult) WelcomeModel {
+    styles := setupResult.Theme.StyleSheet()
+
+    // El tamaño real se ajustará con el primer WindowSizeMsg.
+    vp := viewport.New(100, 20)
+    vp.Style = styles.Base
+    vp.KeyMap = setupResult.Keys.ViewportKeys()
+
+    return WelcomeModel{
+        blocks:            setupResult.ActiveBlocks,
+        viewport:          vp,
+        normalBorderStyle: styles.Border,
+        focusBorderStyle:  styles.FocusedBorder,
+        styles:            styles,
+        statusBar:         setupResult.Config.General.ShowStatusBar(),
+        notes:             shared.NewNotifications(),
+        setup:             setupResult,
+    }
 }
 
 // Init inicializa el estado y los comandos para el modo welcome.
 func (m WelcomeModel) Init() tea.Cmd {
-    // Tu lógica de Init para el dashboard.
-    return func() tea.Msg { return block.TriggerUpdateMsg{} }
-    //return block.TriggerUpdateMsg{}
+    // Un TriggerUpdateMsg inicial para que todos los bloques carguen sus
+    // datos, y el reloj de los pies de bloque.
+    // Los problemas de la config, al panel de mensajes.
+    return tea.Batch(func() tea.Msg { return block.TriggerUpdateMsg{} }, shared.ClockTick(), m.slowHiddenPages(), m.notes.RecordSetup(m.setup))
 }
 
 // Update maneja los mensajes SOLO para el modo welcome.
 func (m WelcomeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-    // Aquí copias la lógica de tu antiguo Update que NO estaba dentro del 'if m.mode == "creating_filter"'.
-    // Manejarás el cambio de foco, el scroll del viewport, 'enter' para expandir, 'q' para salir, etc. 
-    // ...
-    return m, nil // Devuelve el modelo actualizado y los comandos
+    var cmd tea.Cmd
+
+    switch msg := msg.(type) {
+    case tea.WindowSizeMsg:
+        m.width = msg.Width
+        m.height = msg.Height
+        m.resize()
+        m.refreshViewport()
+        return m, nil
+
+    case shared.ClockTickMsg:
+        m.refreshViewport()
+        return m, shared.ClockTick()
+
+    case shared.ConfigChangedMsg:
+        return m.reload(msg.Path)
+
+    case block.NotificationMsg:
+        return m, m.notes.Add(msg)
+
+    case shared.NotificationExpiredMsg:
+        m.notes.Expire(msg)
+        return m, nil
+
+    case tea.KeyMsg:
+        keys := m.setup.Keys
+        if msg.String() == "ctrl+c" {
+            return m, tea.Quit
+        }
+        // Con la ayuda abierta, cualquier tecla la cierra.
+        if m.showHelp {
+            m.showHelp = false
+            return m, nil
+        }
+        typing := m.expanded != nil && m.expanded.typing()
+        if !typing && key.Matches(msg, keys.Help) {
+            m.showHelp = true
+            return m, nil
+        }
+
+        if !typing && key.Matches(msg, keys.Messages) {
+            m.showLog = !m.showLog
+            m.resize()
+            m.refreshViewport()
+            return m, nil
+        }
+
+        // --- VISTA EXPANDIDA ---
+        if m.expanded != nil {
+            cmd, closed := m.expanded.Update(msg)
+            if closed {
+                m.expanded = nil
+                m.refreshViewport()
+            }
+            return m, cmd
+        }
+
+        // --- DASHBOARD ---
+        // El bloque con el foco ve las teclas antes que el dashboard.
+        if len(m.blocks) > 0 {
+            if handled, cmd := sendKey(m.blocks[m.focusIndex], msg); handled {
+                m.refreshViewport()
+                return m, cmd
+            }
+        }
+
+        switch {
+        case key.Matches(msg, keys.Quit):
+            return m, tea.Quit
+
+        case key.Matches(msg, keys.FocusNext):
+            if len(m.blocks) > 0 {
+                m.setFocus(m.layout().NextVisible(m.width, m.blocks, m.focusIndex))
+            }
+            return m, nil
+
+        case key.Matches(msg, keys.FocusPrev):
+            if len(m.blocks) > 0 {
+                m.setFocus(m.layout().PrevVisible(m.width, m.blocks, m.focusIndex))
+            }
+            return m, nil
+
+        case key.Matches(msg, keys.Up, keys.Down, keys.Left, keys.Right):
+            if next := m.layout().Neighbor(m.focusIndex, focusDirection(keys, msg)); next >= 0 {
+                m.setFocus(next)
+                return m, nil
+            }
+            // Sin bloque en esa dirección, arriba y abajo desplazan el dashboard.
+            m.viewport, cmd = m.viewport.Update(msg)
+            return m, cmd
+
+        case key.Matches(msg, keys.ScrollDown, keys.ScrollUp):
+            // Desplazan el bloque con el foco si no le cabe todo; si no, el dashboard.
+            delta := 1
+            if key.Matches(msg, keys.ScrollUp) {
+                delta = -1
+            }
+            if len(m.blocks) > 0 && m.layout().ScrollBy(m.blocks[m.focusIndex].Name(), delta) {
+                m.refreshViewport()
+                return m, nil
+            }
+            m.viewport, cmd = m.viewport.Update(msg)
+            return m, cmd
+
+        case key.Matches(msg, shared.PageKeys):
+            page, _ := strconv.Atoi(msg.String())
+            return m, m.switchPage(page - 1)
+
+        case key.Matches(msg, keys.NextPage, keys.PrevPage):
+            if len(m.setup.Pages) > 0 {
+                step := 1
+                if key.Matches(msg, keys.PrevPage) {
+                    step = len(m.setup.Pages) - 1
+                }
+                return m, m.switchPage((m.page + step) % len(m.setup.Pages))
+            }
+            return m, nil
+
+        case key.Matches(msg, keys.Theme):
+            if m.reloader != nil {
+                name, err := m.reloader.CycleTheme(m.setup, m.blocks)
+                if err != nil {
+                    return m, block.Notify(block.SeverityError, "", "Error cambiando de tema: %v", err)
+                }
+                m.applyTheme()
+                return m, block.Notify(block.SeverityInfo, "", "Tema: %s", name)
+            }
+            return m, nil
+
+        case key.Matches(msg, keys.Expand):
+            if len(m.blocks) == 0 {
+                return m, nil
+            }
+            m.expanded = newExpandedView(m.blocks[m.focusIndex], keys, m.styles, false, m.viewport.Width, m.viewport.Height)
+            return m, nil
+
+        case key.Matches(msg, keys.PageUp, keys.PageDown):
+            m.viewport, cmd = m.viewport.Update(msg)
+            return m, cmd
+
+        case key.Matches(msg, keys.Top):
+            m.viewport.GotoTop()
+        case key.Matches(msg, keys.Bottom):
+            m.viewport.GotoBottom()
+        }
+        return m, nil
+
+    case tea.MouseMsg:
+        if m.showHelp {
+            return m, nil
+        }
+        if m.expanded != nil {
+            cmd, _ = m.expanded.Update(msg)
+            return m, cmd
+        }
+        top := 0
+        if len(m.setup.Pages) > 0 {
+            top = 1 // La barra de pestañas
+        }
+        press := msg.Action == tea.MouseActionPress
+        // Un clic en la barra de pestañas cambia de página.
+        if top > 0 && msg.Y == 0 && press && msg.Button == tea.MouseButtonLeft {
+            if page := shared.TabAt(m.setup.Pages, m.blocks, msg.X); page >= 0 {
+                return m, m.switchPage(page)
+            }
+            return m, nil
+        }
+        // Lo que cae debajo del viewport (panel de mensajes, barra) no cuenta.
+        if msg.Y-top >= m.viewport.Height {
+            return m, nil
+        }
+
+        // Un clic da el foco al bloque; la rueda desplaza el bloque que
+        // está debajo si no le cabe todo y, si no, el dashboard.
+        target := m.layout().BlockAt(msg.X, msg.Y-top+m.viewport.YOffset)
+        switch {
+        case press && msg.Button == tea.MouseButtonLeft:
+            if target >= 0 {
+                m.setFocus(target)
+            }
+            return m, nil
+        case press && target >= 0 && (msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown):
+            delta := m.viewport.MouseWheelDelta
+            if msg.Button == tea.MouseButtonWheelUp {
+                delta = -delta
+            }
+            if m.layout().ScrollBy(m.blocks[target].Name(), delta) {
+                m.refreshViewport()
+                return m, nil
+            }
+        }
+        m.viewport, cmd = m.viewport.Update(msg)
+        return m, cmd
+    }
+
+    // Cualquier otro mensaje es para los bloques, incluso en vista expandida,
+    // para que sigan refrescándose mientras tanto.
+    cmd = tea.Batch(m.updateBlocks(msg), m.notes.Watch(m.blocks))
+    if m.expanded != nil {
+        // El parpadeo del cursor de su prompt, por ejemplo.
+        expandedCmd, _ := m.expanded.Update(msg)
+        cmd = tea.Batch(cmd, expandedCmd)
+    }
+    m.refreshViewport()
+    return m, cmd
+}
+
+// reload vuelve a cargar la config y el tema tras un cambio en disco. Si
+// la config nueva no se puede cargar, se sigue con la anterior.
+func (m WelcomeModel) reload(path string) (tea.Model, tea.Cmd) {
+    if m.reloader == nil {
+        return m, nil
+    }
+    result, cmd, err := m.reloader.Reload(m.setup, m.blocks)
+    if err != nil {
+        logging.Log.Printf("Error reloading config after change in %s: %v", path, err)
+        return m, block.Notify(block.SeverityError, "", "Error recargando la configuración: %v", err)
+    }
+
+    m.setup = result
+    m.blocks = result.ActiveBlocks
+    m.statusBar = result.Config.General.ShowStatusBar()
+    m.viewport.KeyMap = result.Keys.ViewportKeys()
+    if m.page >= len(result.Pages) {
+        m.page = 0
+    }
+    m.resize()
+    if m.focusIndex >= len(m.blocks) {
+        m.focusIndex = 0
+    }
+    if m.expanded != nil && !containsBlock(m.blocks, m.expanded.block) {
+        m.expanded = nil
+    }
+    m.applyTheme()
+    notice := m.notes.RecordSetup(result)
+    if notice == nil {
+        notice = block.Notify(block.SeverityInfo, "", "Configuración recargada")
+    }
+    return m, tea.Batch(cmd, m.slowHiddenPages(), notice)
+}
+
+// layout es el layout de la página que se ve (o el de todo el dashboard
+// si no hay páginas).
+func (m WelcomeModel) layout() *shared.Layout {
+    if m.page < len(m.setup.Pages) {
+        return m.setup.Pages[m.page].Layout
+    }
+    return m.setup.Layout
+}
+
+// setFocus pasa el foco al bloque 'index' y desplaza el dashboard para
+// que se vea.
+func (m *WelcomeModel) setFocus(index int) {
+    m.focusIndex = index
+    m.refreshViewport()
+    if region, ok := m.layout().RegionOf(index); ok {
+        scrollIntoView(&m.viewport, region)
+    }
+}
+
+// switchPage cambia a la página 'page', con el foco en su primer bloque.
+// Los bloques que estaban ralentizados y ahora se ven se ponen al día.
+func (m *WelcomeModel) switchPage(page int) tea.Cmd {
+    if page < 0 || page >= len(m.setup.Pages) || page == m.page {
+        return nil
+    }
+    m.page = page
+    m.focusIndex = max(m.layout().NextVisible(m.width, m.blocks, -1), 0)
+    m.expanded = nil
+    m.resize()
+    m.refreshViewport()
+    m.viewport.GotoTop()
+    return m.slowHiddenPages()
+}
+
+// slowHiddenPages ralentiza los bloques de las páginas que no se ven.
+func (m WelcomeModel) slowHiddenPages() tea.Cmd {
+    return block.SetSlowed(shared.SlowBlocks(m.setup.Pages, m.page), shared.HiddenPageRefresh(m.setup.Config))
+}
+
+// resize ajusta el viewport a la ventana, dejando sitio a la barra de estado.
+func (m *WelcomeModel) resize() {
+    m.viewport.Width = m.width
+    m.viewport.Height = m.height
+    if m.statusBar && m.viewport.Height > 1 {
+        m.viewport.Height--
+    }
+    if len(m.setup.Pages) > 0 && m.viewport.Height > 1 {
+        m.viewport.Height-- // La barra de pestañas
+    }
+    m.logHeight = 0
+    if m.showLog && m.viewport.Height > 2*shared.MessageLogHeight {
+        m.logHeight = shared.MessageLogHeight
+        m.viewport.Height -= m.logHeight
+    }
+    if m.expanded != nil {
+        m.expanded.setSize(m.viewport.Width, m.viewport.Height)
+    }
+    // Los breakpoints y el ancho de cada bloque dependen del de la ventana.
+    layout := m.layout()
+    layout.Resize(m.width, m.blocks, m.normalBorderStyle)
+    // El foco no puede quedarse en un bloque que ya no se ve.
+    if m.focusIndex < len(m.blocks) && !layout.Visible(m.width, m.blocks[m.focusIndex]) {
+        m.focusIndex = max(layout.NextVisible(m.width, m.blocks, -1), 0)
+    }
+}
+
+// applyTheme aplica el tema actual a los estilos propios del modelo.
+func (m *WelcomeModel) applyTheme() {
+    styles := m.setup.Theme.StyleSheet()
+    m.styles = styles
+    m.viewport.Style = styles.Base
+    m.normalBorderStyle = styles.Border
+    m.focusBorderStyle = styles.FocusedBorder
+    if m.expanded != nil {
+        m.expanded.setStyles(m.setup.Keys, styles)
+    }
+    m.refreshViewport()
+}
+
+// containsBlock indica si 'target' sigue entre los bloques activos.
+func containsBlock(blocks []block.Block, target block.Block) bool {
+    for _, b := range blocks {
+        if b == target {
+            return true
+        }
+    }
+    return false
+}
+
+// updateBlocks reparte un mensaje entre los bloques. Los mensajes dirigidos
+// (TargetedMsg) solo llegan a su destinatario; el resto se difunde a todos.
+func (m *WelcomeModel) updateBlocks(msg tea.Msg) tea.Cmd {
+    var cmds []tea.Cmd
+
+    // Un tick sustituido por otro posterior ya no toca.
+    if tick, ok := msg.(block.BlockTickMsg); ok && tick.Stale() {
+        return nil
+    }
+
+    if targetMsg, ok := msg.(block.TargetedMsg); ok {
+        targetID := targetMsg.BlockID()
+        for i, b := range m.blocks {
+            if b.Name() == targetID {
+                updatedBlock, blockCmd := b.Update(msg)
+                m.blocks[i] = updatedBlock
+                cmds = append(cmds, blockCmd)
+                break
+            }
+        }
+    } else {
+        for i, b := range m.blocks {
+            updatedBlock, blockCmd := b.Update(msg)
+            m.blocks[i] = updatedBlock
+            cmds = append(cmds, blockCmd)
+        }
+    }
+
+    return tea.Batch(cmds...)
+}
+
+// refreshViewport vuelca en el viewport el contenido del estado actual:
+// la vista expandida del bloque o el dashboard completo.
+func (m *WelcomeModel) refreshViewport() {
+    if m.expanded != nil {
+        m.expanded.refresh()
+        return
+    }
+
+    m.viewport.SetContent(m.layout().Render(
+        m.width,
+        m.blocks,
+        m.focusIndex,
+        m.normalBorderStyle,
+        m.focusBorderStyle,
+    ))
 }
 
 // View renderiza la UI del modo welcome.
 func (m WelcomeModel) View() string {
-    // Aquí copias la lógica de tu antiguo View que renderizaba el dashboard.
-    // ...
-    return m.viewport.View()
+    if m.width == 0 {
+        return "Initializing..."
+    }
+    views := []string{m.viewport.View()}
+    if m.expanded != nil {
+        views[0] = m.expanded.View()
+    }
+    if m.showHelp {
+        groups := m.setup.Keys.DashboardHelp(len(m.setup.Pages) > 0, false)
+        if m.expanded != nil {
+            groups = m.setup.Keys.ExpandedHelp(false, m.expanded.live)
+        }
+        views[0] = shared.RenderHelp(m.viewport.Width, m.viewport.Height, m.styles, groups)
+    }
+    if m.logHeight > 0 {
+        views = append(views, m.notes.RenderLog(m.width, m.logHeight, m.styles))
+    }
+    if len(m.setup.Pages) > 0 {
+        views = append([]string{shared.RenderTabBar(m.width, m.styles, m.setup.Pages, m.page, m.blocks)}, views...)
+    }
+    content := m.notes.Overlay(lipgloss.JoinVertical(lipgloss.Left, views...), m.width, m.styles)
+    if !m.statusBar {
+        return content
+    }
+
+    km := m.setup.Keys
+    keys := shared.KeyHints(km.FocusNext, km.Expand, km.Messages, km.Help, km.Quit)
+    if len(m.setup.Pages) > 0 {
+        keys = shared.KeyHints(shared.PageKeys) + " · " + keys
+    }
+    if m.expanded != nil {
+        keys = shared.KeyHints(km.Back, km.Search, km.FilterLines, km.Copy, km.Help)
+        if m.expanded.typing() {
+            keys = shared.KeyHints(km.InputHelp()...)
+        }
+    }
+    bar := shared.RenderStatusBar(m.width, m.styles, keys, m.blocks, m.focusIndex)
+    return lipgloss.JoinVertical(lipgloss.Left, content, bar)
 }
 
 // RunWelcomeTUI lanza la aplicación interactiva para 'welcome'.
 func RunWelcomeTUI(refreshTarget string) error {
-    fmt.Println("TODO: Implementar modo TUI para 'welcome'")
-    // Aquí irá la lógica de Bubble Tea para 'welcome'
-    
-    // Creas el modelo específico.
-    initialModel := WelcomeModel{
-        // ... inicializas los campos ...
+    setupResult, err := shared.Setup(refreshTarget, shared.RunModeTUI)
+    if err != nil {
+        return fmt.Errorf("error al inicializar la configuración: %w", err)
+    }
+
+    // Recarga en caliente: la config y los temas se vigilan mientras dura la TUI.
+    reloader := shared.NewReloader()
+    defer reloader.Close()
+
+    initialModel := NewWelcomeModel(setupResult)
+    initialModel.reloader = reloader
+    p := tea.NewProgram(initialModel, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(terminal))
+    reloader.SetProgram(p)
+    if err := reloader.Watch(setupResult.Config); err != nil {
+        logging.Log.Printf("Config hot-reload disabled: %v", err)
     }
 
-    p := tea.NewProgram(initialModel, tea.WithAltScreen())
-    _, err := p.Run()
-    return err
+    // Los bloques en streaming necesitan el programa para enviar sus líneas.
+    for _, b := range initialModel.blocks {
+        if streamer, ok := b.(block.Streamer); ok {
+            streamer.SetProgram(p)
+        }
+    }
+
+    logging.Log.Printf("Starting welcome TUI with %d blocks", len(initialModel.blocks))
+    finalModel, err := p.Run()
+    // Tras una recarga los bloques en marcha ya no son los iniciales.
+    if final, ok := finalModel.(WelcomeModel); ok {
+        shared.CloseBlocks(final.blocks)
+    } else {
+        shared.CloseBlocks(initialModel.blocks)
+    }
+    if err != nil {
+        log.Printf("Error al ejecutar el programa TUI: %v", err)
+        return err
+    }
+
+    // La pantalla alternativa taparía los problemas; los mostramos al salir.
+    shared.ReportProblems(os.Stderr, setupResult.Problems)
+
+    return nil
 }
 
 // RunWelcomeTTY ejecuta la lógica de volcado en texto plano para 'welcome'.
+// Cada bloque se actualiza una sola vez (con su timeout) y el layout
+// compuesto se imprime en stdout, apto para /etc/profile.d o un MOTD.
 func RunWelcomeTTY(refreshTarget string) error {
-    fmt.Println("TODO: Implementar modo TTY para 'welcome'")
-    // Aquí irá la lógica de ejecución síncrona y volcado a consola
+    setupResult, err := shared.Setup(refreshTarget, shared.RunModeTTY)
+    if err != nil {
+        return fmt.Errorf("error al inicializar la configuración: %w", err)
+    }
+
+    shared.ReportProblems(os.Stderr, setupResult.Problems)
+
+    blocks := setupResult.ActiveBlocks
+    defer shared.CloseBlocks(blocks)
+
+    shared.NewDriver(blocks).Run(setupResult.BlockTimeout)
+
+    styles := setupResult.Theme.StyleSheet()
+    border := styles.Border
+    width := ttyWidth()
+
+    // Sin foco (-1): en texto plano no hay bloque seleccionado.
+    if len(setupResult.Pages) == 0 {
+        setupResult.Layout.Resize(width, blocks, border)
+        fmt.Println(setupResult.Layout.Render(width, blocks, -1, border, border))
+        return nil
+    }
+    // Con páginas no hay pestañas que cambiar: van una detrás de otra.
+    for _, page := range setupResult.Pages {
+        page.Layout.Resize(width, blocks, border)
+        fmt.Println(styles.Title.Render(" " + page.Title + " "))
+        fmt.Println(page.Layout.Render(width, blocks, -1, border, border))
+    }
     return nil
-}
\ No newline at end of file
+}
+
+// ttyWidth devuelve el ancho de la terminal, o $COLUMNS / 80 si la salida
+// no es una terminal (redirección, MOTD, cron...).
+func ttyWidth() int {
+    if width, _, err := term.GetSize(os.Stdout.Fd()); err == nil && width > 0 {
+        return width
+    }
+    if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+        return width
+    }
+    return 80
+}
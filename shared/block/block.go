@@ -2,19 +2,27 @@
 package block
 
 import (
+	"sync"
+	"sync/atomic"
 	"time"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbletea"
 	//"github.com/charmbracelet/lipgloss"
 	"github.com/gas/fancy-welcome/config" // Importamos el paquete de config para uso particular
 	"github.com/gas/fancy-welcome/themes"
 	"github.com/gas/fancy-welcome/logging"
+	"github.com/gas/fancy-welcome/shared/data"
 )
 
 type TriggerUpdateMsg struct{}
 
+// TeeOutputMsg difunde la salida de un bloque a los que le escuchan.
+// Streamed indica que Output es un lote incremental de un stream y no
+// la salida completa del comando.
 type TeeOutputMsg struct {
 	SourceBlockID string
-	Output      interface{}
+	Output      data.Value
+	Streamed    bool
 }
 
 // STREAM
@@ -26,22 +34,131 @@ type StreamLineBatchMsg struct {
 // Hacemos que cumpla la interfaz para ser un mensaje dirigido.
 func (m StreamLineBatchMsg) BlockID() string { return m.blockID }
 
+// Sender es lo mínimo que un bloque necesita para inyectar mensajes de forma
+// asíncrona. *tea.Program lo cumple, y también el Driver de los modos --simple.
+type Sender interface {
+	Send(msg tea.Msg)
+}
+
 // Streamer es una interfaz que pueden implementar los bloques que necesitan
 // una referencia al programa para enviar mensajes de forma continua (streaming).
 type Streamer interface {
-	SetProgram(p *tea.Program)
+	SetProgram(p Sender)
+}
+
+// Closer lo implementan los bloques que lanzan procesos o recursos que hay
+// que liberar al salir (por ejemplo, un comando en streaming).
+type Closer interface {
+	Close() error
 }
 
 // BlockTickMsg es el mensaje que se enviará periódicamente a un bloque específico.
 type BlockTickMsg struct {
 	targetBlockID string
+	seq           int // Para saber si otro tick lo ha sustituido
 }
 // Hacemos que cumpla la interfaz para ser un mensaje dirigido.
 func (m BlockTickMsg) BlockID() string { return m.targetBlockID }
 
+// Stale indica si después de este tick se ha programado otro para el mismo
+// bloque (con ScheduleNextTick, Wake o NewBlockTickMsg). Los modelos lo
+// descartan: cada bloque tiene una sola cadena de ticks.
+func (m BlockTickMsg) Stale() bool {
+	ticks.mu.Lock()
+	defer ticks.mu.Unlock()
+	return m.seq != ticks.seq[m.targetBlockID]
+}
+
+// NewBlockTickMsg crea un tick para un bloque. Sirve para arrancar un solo
+// bloque sin difundir un TriggerUpdateMsg a todos (por ejemplo, al recargar).
+func NewBlockTickMsg(blockID string) tea.Msg {
+	ticks.mu.Lock()
+	defer ticks.mu.Unlock()
+	delete(ticks.since, blockID)
+	delete(ticks.due, blockID)
+	return BlockTickMsg{targetBlockID: blockID, seq: ticks.next(blockID)}
+}
+
+// tickState es el estado de los ticks programados, por bloque.
+type tickState struct {
+	mu       sync.Mutex
+	seq      map[string]int           // Último tick programado
+	since    map[string]time.Time     // Cuándo se programó el tick pendiente
+	due      map[string]time.Time     // Cuándo llega
+	interval map[string]time.Duration // Intervalo pedido, sin ralentizar
+	slowed   map[string]bool          // Bloques en una página oculta
+	factor   float64                  // Cuánto más despacio van los ralentizados
+}
+
+var ticks = tickState{
+	seq:      make(map[string]int),
+	since:    make(map[string]time.Time),
+	due:      make(map[string]time.Time),
+	interval: make(map[string]time.Duration),
+	slowed:   make(map[string]bool),
+	factor:   1,
+}
+
+// next invalida los ticks pendientes de un bloque y devuelve el número del
+// siguiente. Hay que llamarla con mu cogido.
+func (t *tickState) next(blockID string) int {
+	t.seq[blockID]++
+	return t.seq[blockID]
+}
+
+// schedule programa un tick para dentro de 'delay'. Hay que llamarla con mu cogido.
+func (t *tickState) schedule(blockID string, delay time.Duration) tea.Cmd {
+	seq := t.next(blockID)
+	t.since[blockID] = time.Now()
+	t.due[blockID] = t.since[blockID].Add(delay)
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return BlockTickMsg{targetBlockID: blockID, seq: seq}
+	})
+}
+
+// SetSlowed ralentiza los bloques 'ids' (los de las páginas que no se ven):
+// sus ticks se espacian 'factor' veces más. Sustituye a la llamada anterior.
+// Los bloques que dejan de estar ralentizados vuelven a su ritmo ya: el
+// comando devuelto adelanta su tick pendiente a cuando les tocaría, o a
+// ahora mismo si ya se ha pasado.
+func SetSlowed(ids []string, factor float64) tea.Cmd {
+	ticks.mu.Lock()
+	defer ticks.mu.Unlock()
+
+	slowed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		slowed[id] = true
+	}
+	var cmds []tea.Cmd
+	for id := range ticks.slowed {
+		since, pending := ticks.since[id]
+		if slowed[id] || !pending {
+			continue
+		}
+		cmds = append(cmds, ticks.schedule(id, max(time.Until(since.Add(ticks.interval[id])), 0)))
+	}
+	ticks.slowed, ticks.factor = slowed, max(factor, 1)
+	return tea.Batch(cmds...)
+}
+
+// NextTick devuelve cuándo llega el tick pendiente de un bloque, o cero si
+// no tiene ninguno. Es lo que muestran los pies de bloque.
+func NextTick(blockID string) time.Time {
+	ticks.mu.Lock()
+	defer ticks.mu.Unlock()
+	return ticks.due[blockID]
+}
+
+// scale multiplica un intervalo.
+func scale(interval time.Duration, factor float64) time.Duration {
+	return time.Duration(float64(interval) * factor)
+}
+
 // Block es la interfaz que cada módulo de bloque debe implementar.
 type Block interface {
-	Init(blockConfig map[string]interface{}, globalConfig config.GeneralConfig, theme *themes.Theme) error
+	// Init recibe los estilos ya resueltos para este bloque: el tema con
+	// los ajustes de su tabla 'style' aplicados.
+	Init(blockConfig map[string]interface{}, globalConfig config.GeneralConfig, styles *themes.StyleSheet) error
 	// El nuevo Update recibe el mensaje y devuelve el bloque actualizado y un comando.
 	Update(msg tea.Msg) (Block, tea.Cmd) //<--STREAM sin p
 	View() string
@@ -51,22 +168,118 @@ type Block interface {
 	RendererName() string
 }
 
-// ScheduleNextTick devuelve un comando que envía un TriggerUpdateMsg después de un intervalo.
+// oneShot desactiva la planificación de ticks. En los modos de volcado
+// (--simple) cada bloque se actualiza una sola vez y no hay siguiente tick.
+// Lo leen los comandos de los bloques, cada uno en su goroutine.
+var oneShot atomic.Bool
+
+// SetOneShot activa o desactiva el modo de una sola actualización.
+func SetOneShot(enabled bool) { oneShot.Store(enabled) }
+
+// OneShot indica si está activo el modo de una sola actualización.
+func OneShot() bool { return oneShot.Load() }
+
+// ScheduleNextTick devuelve un comando que envía un BlockTickMsg después de
+// un intervalo (más largo si el bloque está ralentizado, ver SetSlowed).
+// Sustituye al tick que el bloque tuviera pendiente.
 func ScheduleNextTick(blockID string, interval time.Duration) tea.Cmd {
+	if OneShot() {
+		return nil
+	}
+	ticks.mu.Lock()
+	defer ticks.mu.Unlock()
+
+	ticks.interval[blockID] = interval
+	if ticks.slowed[blockID] {
+		interval = scale(interval, ticks.factor)
+	}
 	logging.Log.Printf(">>> Scheduling next TICK for [%s] in %v", blockID, interval)
-	return tea.Tick(interval, func(t time.Time) tea.Msg {
-		return BlockTickMsg{targetBlockID: blockID}
-	})
+	return ticks.schedule(blockID, interval)
 }
 
 type TargetedMsg interface {
 	BlockID() string
 }
 
+// Styler lo implementan los bloques que guardan sus estilos, para que el
+// layout dibuje su marco con ellos (borde, colores, padding propios).
+type Styler interface {
+	Styles() *themes.StyleSheet
+}
+
+// Restyler lo implementan los bloques que pueden cambiar de estilos sin
+// reinicializarse, para cambiar de tema en caliente sin perder su estado.
+type Restyler interface {
+	SetStyles(styles *themes.StyleSheet)
+}
+
+// Sizer lo implementan los bloques que adaptan su contenido al ancho que
+// les da el layout (el de dentro de su marco).
+type Sizer interface {
+	SetWidth(width int)
+}
+
+// Interactive lo implementan los bloques con controles propios. Con el
+// foco, reciben las teclas antes que el dashboard; si devuelven false, la
+// tecla sigue su camino (foco, scroll, atajos).
+type Interactive interface {
+	HandleKey(msg tea.KeyMsg) (bool, tea.Cmd)
+}
+
+// Keys son las teclas de [keys] que atienden los bloques.
+type Keys struct {
+	Refresh key.Binding // Actualizar el bloque ya (o relanzar su stream)
+}
+
+// KeyBinder lo implementan los bloques interactivos que usan teclas de
+// [keys]. Las reciben al crearse y tras cada recarga de la config.
+type KeyBinder interface {
+	SetKeys(keys Keys)
+}
+
 type Expander interface {
 	ExpandedView() string
 }
 
+// Follower lo implementan los bloques cuyo contenido crece por el final,
+// como un stream. Si Follows es true, la vista expandida sigue el final
+// hasta que el usuario la pausa o se desplaza hacia arriba.
+type Follower interface {
+	Follows() bool
+}
+
+// Chrome son los adornos del marco de un bloque que se configuran con las
+// claves comunes 'title' y 'footer'.
+type Chrome struct {
+	Title  string // Título en el borde de arriba; vacío = sin título
+	Footer bool   // Pie con la edad de los datos y la próxima actualización
+}
+
+// ChromeFrom lee 'title' y 'footer' de la config de un bloque.
+func ChromeFrom(blockConfig map[string]interface{}) Chrome {
+	var c Chrome
+	c.Title, _ = blockConfig["title"].(string)
+	c.Footer, _ = blockConfig["footer"].(bool)
+	return c
+}
+
+// Status es lo que un bloque cuenta de sí mismo para que el layout lo
+// muestre en su marco y en la barra de estado. Los campos a cero no se
+// muestran.
+type Status struct {
+	Chrome
+	UpdatedAt   time.Time // Cuándo se obtuvieron los datos que se muestran
+	NextRefresh time.Time // Próxima actualización; cero si no se refresca solo
+	Loading     bool
+	Spinner     string // Fotograma actual del indicador de carga
+	Err         error
+}
+
+// Reporter lo implementan los bloques que informan de su estado.
+type Reporter interface {
+	Status() Status
+}
+
 // NewStreamLineBatchMsg es un constructor público para crear el mensaje.
 func NewStreamLineBatchMsg(id string, lines []string) tea.Msg {
 	return StreamLineBatchMsg{
@@ -74,18 +287,3 @@ func NewStreamLineBatchMsg(id string, lines []string) tea.Msg {
 		Lines:   lines, // Correcto: este campo es público de todas formas
 	}
 }
-
-// streamClosedMsg notifica que un comando en modo streaming ha terminado o ha fallado.
-type streamClosedMsg struct {
-	blockID string // <--- 'b' minúscula
-	err     error
-}
-func (m streamClosedMsg) BlockID() string { return m.blockID }
-
-// NewStreamClosedMsg es el constructor público para el mensaje de cierre.
-func NewStreamClosedMsg(id string, err error) tea.Msg {
-	return streamClosedMsg{
-		blockID: id,
-		err:     err,
-	}
-}
\ No newline at end of file
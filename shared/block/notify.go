@@ -0,0 +1,59 @@
+// shared/block/notify.go
+package block
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Severity es la gravedad de una notificación: decide su color y su marca.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeveritySuccess
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeveritySuccess:
+		return "ok"
+	case SeverityWarning:
+		return "aviso"
+	case SeverityError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// NotificationMsg es un aviso para el usuario que puede emitir cualquier
+// bloque o modo (como mensaje de un comando o con Sender.Send). La TUI lo
+// enseña un momento abajo y lo guarda en el panel de mensajes; en los
+// modos --simple solo va al log.
+type NotificationMsg struct {
+	Severity Severity
+	Source   string // Bloque que lo emite; "" si es de la aplicación
+	Text     string
+	Time     time.Time
+}
+
+// Notify devuelve el comando que emite una notificación.
+func Notify(severity Severity, source, format string, args ...interface{}) tea.Cmd {
+	msg := NewNotification(severity, source, format, args...)
+	return func() tea.Msg { return msg }
+}
+
+// NewNotification construye una notificación con la hora actual.
+func NewNotification(severity Severity, source, format string, args ...interface{}) NotificationMsg {
+	return NotificationMsg{
+		Severity: severity,
+		Source:   source,
+		Text:     fmt.Sprintf(format, args...),
+		Time:     time.Now(),
+	}
+}
@@ -0,0 +1,218 @@
+// shared/block/schema.go
+package block
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// FieldType es el tipo TOML que se espera en una clave de la config de un bloque.
+type FieldType string
+
+const (
+	TypeString   FieldType = "string"
+	TypeInt      FieldType = "entero"
+	TypeNumber   FieldType = "número"
+	TypeBool     FieldType = "booleano"
+	TypeTable    FieldType = "tabla"
+	TypeBlockRef FieldType = "nombre de bloque" // Un string que debe ser otro bloque de la config.
+)
+
+// Field describe una clave de la config de un bloque.
+type Field struct {
+	Key      string
+	Type     FieldType
+	Required bool
+	Enum     []string    // Valores permitidos (solo para strings), vacío si es libre.
+	Default  interface{} // Se escribe en la config si la clave falta.
+	Doc      string
+}
+
+// Schema es la lista de claves que acepta un tipo de bloque.
+type Schema struct {
+	Fields []Field
+}
+
+// Configurable lo implementan los bloques que declaran su schema. Setup
+// valida la config del bloque con él antes de llamar a Init.
+type Configurable interface {
+	ConfigSchema() Schema
+}
+
+// CommonFields son las claves que entiende cualquier bloque, además de las suyas.
+var CommonFields = []Field{
+	{Key: "type", Type: TypeString, Required: true, Doc: "tipo de bloque"},
+	{Key: "position", Type: TypeString, Enum: []string{"left", "right", "full-width"}, Doc: "columna del dashboard"},
+	{Key: "run_mode", Type: TypeString, Enum: []string{"all", "tui", "tty"}, Doc: "modo en el que se ejecuta"},
+	{Key: "timeout", Type: TypeNumber, Doc: "espera máxima en modo --simple, en segundos"},
+	{Key: "style", Type: TypeTable, Doc: "ajustes de estilo del bloque"},
+	{Key: "title", Type: TypeString, Doc: "título en el borde del bloque"},
+	{Key: "footer", Type: TypeBool, Doc: "pie con la edad de los datos y la próxima actualización"},
+	{Key: "column", Type: TypeInt, Doc: "columna de la rejilla en la que empieza (1..N)"},
+	{Key: "span", Type: TypeInt, Doc: "columnas que ocupa"},
+	{Key: "row_span", Type: TypeInt, Doc: "filas que ocupa"},
+	{Key: "min_height", Type: TypeInt, Doc: "alto mínimo, con el marco"},
+	{Key: "max_height", Type: TypeInt, Doc: "alto máximo, con el marco"},
+	{Key: "priority", Type: TypeString, Enum: []string{"low", "normal", "high"}, Doc: "prioridad frente a los breakpoints con 'min_priority'"},
+	{Key: "overflow", Type: TypeString, Enum: []string{"truncate", "scroll"}, Doc: "qué hacer con lo que no cabe en max_height"},
+}
+
+// Issue es un problema en una clave de la config de un bloque. Key vacío
+// significa que afecta al bloque entero. Warning indica que el bloque se
+// puede usar igualmente.
+type Issue struct {
+	Key     string
+	Message string
+	Warning bool
+}
+
+// Validate comprueba la config de un bloque contra el schema (más
+// CommonFields) y rellena los valores por defecto de las claves que faltan.
+// 'blocks' son los nombres de bloque definidos, para las TypeBlockRef.
+func (s Schema) Validate(blockConfig map[string]interface{}, blocks []string) []Issue {
+	var issues []Issue
+	fields := append(append([]Field{}, CommonFields...), s.Fields...)
+
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.Key] = true
+
+		value, ok := blockConfig[field.Key]
+		if !ok {
+			if field.Required {
+				issues = append(issues, Issue{Key: field.Key, Message: fmt.Sprintf("falta la clave obligatoria (%s)", field.Doc)})
+			} else if field.Default != nil {
+				blockConfig[field.Key] = field.Default
+			}
+			continue
+		}
+
+		if msg := field.check(value, blocks); msg != "" {
+			issues = append(issues, Issue{Key: field.Key, Message: msg})
+		}
+	}
+
+	// Claves que nadie lee: casi siempre una errata.
+	var unknown []string
+	for key := range blockConfig {
+		if !known[key] && key != "name" {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	candidates := make([]string, 0, len(fields))
+	for _, field := range fields {
+		candidates = append(candidates, field.Key)
+	}
+	for _, key := range unknown {
+		issues = append(issues, Issue{Key: key, Message: "clave desconocida" + Suggestion(key, candidates), Warning: true})
+	}
+	return issues
+}
+
+// check devuelve un mensaje si 'value' no cumple el campo, o "" si es válido.
+func (f Field) check(value interface{}, blocks []string) string {
+	switch f.Type {
+	case TypeString, TypeBlockRef:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("se esperaba un string, no %s", tomlType(value))
+		}
+		if len(f.Enum) > 0 && !contains(f.Enum, str) {
+			return fmt.Sprintf("valor %q no válido%s (válidos: %s)", str, Suggestion(str, f.Enum), strings.Join(f.Enum, ", "))
+		}
+		if f.Type == TypeBlockRef && !contains(blocks, str) {
+			return fmt.Sprintf("el bloque %q no está definido%s", str, Suggestion(str, blocks))
+		}
+	case TypeInt:
+		switch v := value.(type) {
+		case int64:
+		case float64:
+			if v != math.Trunc(v) {
+				return fmt.Sprintf("se esperaba un entero, no %v", v)
+			}
+		default:
+			return fmt.Sprintf("se esperaba un entero, no %s", tomlType(value))
+		}
+	case TypeNumber:
+		switch value.(type) {
+		case int64, float64:
+		default:
+			return fmt.Sprintf("se esperaba un número, no %s", tomlType(value))
+		}
+	case TypeBool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("se esperaba true o false, no %s", tomlType(value))
+		}
+	case TypeTable:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Sprintf("se esperaba una tabla, no %s", tomlType(value))
+		}
+	}
+	return ""
+}
+
+// tomlType describe el tipo de un valor decodificado con los nombres de TOML.
+func tomlType(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("el string %q", v)
+	case int64, float64:
+		return fmt.Sprintf("el número %v", v)
+	case bool:
+		return fmt.Sprintf("el booleano %v", v)
+	case []interface{}:
+		return "un array"
+	case map[string]interface{}:
+		return "una tabla"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggestion devuelve ", ¿quisiste decir X?" si algún candidato se parece a s.
+func Suggestion(s string, candidates []string) string {
+	best, bestDist := "", 3 // Más de dos cambios ya no parece una errata.
+	for _, c := range candidates {
+		if d := distance(s, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", ¿quisiste decir %q?", best)
+}
+
+// distance es la distancia de edición (Levenshtein) entre a y b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr := make([]int, len(rb)+1)
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev = curr
+	}
+	return prev[len(rb)]
+}
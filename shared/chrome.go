@@ -0,0 +1,170 @@
+// shared/chrome.go
+package shared
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/charmbracelet/bubbletea"
+    "github.com/charmbracelet/lipgloss"
+    "github.com/charmbracelet/x/ansi"
+
+    "github.com/gas/fancy-welcome/shared/block"
+    "github.com/gas/fancy-welcome/themes"
+)
+
+// ClockTickMsg llega cada segundo para que los pies de bloque (la edad de
+// los datos, la próxima actualización) avancen aunque no lleguen datos.
+type ClockTickMsg time.Time
+
+// ClockTick programa el siguiente ClockTickMsg.
+func ClockTick() tea.Cmd {
+    return tea.Tick(time.Second, func(t time.Time) tea.Msg { return ClockTickMsg(t) })
+}
+
+// frameBlock dibuja 'content' dentro del marco 'border' y mete en el borde
+// de arriba el título del bloque (y una marca si tiene un error) y en el de
+// abajo su pie. 'styles' puede ser nil si el bloque no tiene estilos propios.
+func frameBlock(content string, border lipgloss.Style, styles *themes.StyleSheet, status block.Status) string {
+    framed := border.Render(content)
+
+    titleStyle, errorStyle, mutedStyle := lipgloss.NewStyle().Bold(true), lipgloss.NewStyle().Bold(true), lipgloss.NewStyle().Faint(true)
+    align := lipgloss.Left
+    if styles != nil {
+        titleStyle, errorStyle, mutedStyle = styles.Title, styles.Error.Bold(true), styles.Muted
+        align = styles.TitleAlign
+    }
+
+    var top []string
+    if status.Title != "" {
+        top = append(top, titleStyle.Render(" "+status.Title+" "))
+    }
+    if status.Err != nil {
+        top = append(top, errorStyle.Render("✗ error"))
+    }
+    var bottom string
+    if status.Footer {
+        if text := footerText(status, time.Now()); text != "" {
+            bottom = mutedStyle.Render(text)
+        }
+    }
+    if len(top) == 0 && bottom == "" {
+        return framed
+    }
+
+    lines := strings.Split(framed, "\n")
+    edges := border.GetBorderStyle()
+    paint := lipgloss.NewStyle().
+        Foreground(border.GetBorderTopForeground()).
+        Background(border.GetBorderTopBackground())
+
+    if len(top) > 0 {
+        label := strings.Join(top, " ")
+        if border.GetBorderTop() {
+            lines[0] = borderLine(edges.TopLeft, edges.Top, edges.TopRight, lipgloss.Width(lines[0]), label, align, paint)
+        } else {
+            // Sin borde de arriba (border = "none"), el título va en su propia línea.
+            lines = append([]string{label}, lines...)
+        }
+    }
+    if bottom != "" {
+        last := len(lines) - 1
+        if border.GetBorderBottom() {
+            lines[last] = borderLine(edges.BottomLeft, edges.Bottom, edges.BottomRight, lipgloss.Width(lines[last]), bottom, lipgloss.Right, paint)
+        } else {
+            lines = append(lines, bottom)
+        }
+    }
+    return strings.Join(lines, "\n")
+}
+
+// borderLine compone una línea de borde de 'width' celdas con 'label'
+// dentro, rodeada de un espacio. Si no cabe, la recorta.
+func borderLine(left, fill, right string, width int, label string, align lipgloss.Position, paint lipgloss.Style) string {
+    inner := width - lipgloss.Width(left) - lipgloss.Width(right)
+    if inner < 5 || fill == "" {
+        return paint.Render(left + strings.Repeat(fill, max(inner, 0)) + right)
+    }
+
+    // Un relleno a cada lado como mínimo, más los espacios alrededor.
+    if lipgloss.Width(label) > inner-4 {
+        label = ansi.Truncate(label, inner-4, "…")
+    }
+    label = " " + label + " "
+    free := inner - lipgloss.Width(label)
+
+    before := 1
+    switch align {
+    case lipgloss.Center:
+        before = free / 2
+    case lipgloss.Right:
+        before = free - 1
+    }
+    return paint.Render(left+strings.Repeat(fill, before)) + label + paint.Render(strings.Repeat(fill, free-before)+right)
+}
+
+// footerText es el pie de un bloque: el indicador de carga o la edad de los
+// datos y cuándo se vuelven a pedir.
+func footerText(status block.Status, now time.Time) string {
+    var parts []string
+    if status.Loading {
+        parts = append(parts, strings.TrimSpace(status.Spinner+" actualizando"))
+    } else if !status.UpdatedAt.IsZero() {
+        parts = append(parts, "hace "+formatAge(now.Sub(status.UpdatedAt)))
+    }
+    if !status.Loading && !status.NextRefresh.IsZero() {
+        parts = append(parts, "próximo en "+formatAge(status.NextRefresh.Sub(now)))
+    }
+    return strings.Join(parts, " · ")
+}
+
+// formatAge da una duración con la unidad más grande que tenga sentido:
+// 12s, 5m, 3h, 2d.
+func formatAge(d time.Duration) string {
+    switch {
+    case d < time.Second:
+        return "0s"
+    case d < time.Minute:
+        return fmt.Sprintf("%ds", int(d.Seconds()))
+    case d < time.Hour:
+        return fmt.Sprintf("%dm", int(d.Minutes()))
+    case d < 24*time.Hour:
+        return fmt.Sprintf("%dh", int(d.Hours()))
+    }
+    return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// RenderStatusBar compone la barra de estado de la TUI: las teclas
+// disponibles a la izquierda y, a la derecha, el bloque con el foco y
+// cuántos bloques tienen errores. Usa los colores de título del tema.
+func RenderStatusBar(width int, styles *themes.StyleSheet, keys string, blocks []block.Block, focusIndex int) string {
+    if width <= 0 {
+        return ""
+    }
+    bar := styles.Title.Bold(false)
+
+    failing := 0
+    for _, b := range blocks {
+        if reporter, ok := b.(block.Reporter); ok && reporter.Status().Err != nil {
+            failing++
+        }
+    }
+
+    var right []string
+    if focusIndex >= 0 && focusIndex < len(blocks) {
+        right = append(right, bar.Render("foco: "+blocks[focusIndex].Name()))
+    }
+    if failing > 0 {
+        errorBadge := styles.Error.Background(styles.Title.GetBackground()).Bold(true)
+        right = append(right, errorBadge.Render(fmt.Sprintf("✗ %d con error", failing)))
+    }
+    rightText := strings.Join(right, bar.Render(" · ")) + bar.Render(" ")
+
+    leftWidth := width - lipgloss.Width(rightText)
+    if leftWidth < 1 {
+        return ansi.Truncate(rightText, width, "")
+    }
+    left := ansi.Truncate(" "+keys, leftWidth-1, "…")
+    return bar.Width(leftWidth).Render(left) + rightText
+}
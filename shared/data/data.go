@@ -0,0 +1,172 @@
+// shared/data/data.go
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind identifica el tipo de resultado de un parser. Se guarda junto a los
+// datos en la caché para poder reconstruir el tipo concreto al leerla.
+type Kind string
+
+const (
+	KindText     Kind = "text"
+	KindLines    Kind = "lines"
+	KindTable    Kind = "table"
+	KindKeyValue Kind = "key_value"
+	KindMetrics  Kind = "metrics"
+	KindTree     Kind = "tree"
+)
+
+// Value es el resultado de un parser: uno de los tipos de este paquete.
+// Los renderers trabajan con estos tipos concretos, vengan de un comando
+// recién ejecutado o de la caché.
+type Value interface {
+	Kind() Kind
+}
+
+// Text es un bloque de texto tal cual.
+type Text string
+
+// Lines es una lista de líneas.
+type Lines []string
+
+// Table es una tabla; la primera fila es la cabecera.
+type Table [][]string
+
+// Pair es una entrada de KeyValue.
+type Pair struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// KeyValue es una lista ordenada de pares clave/valor. Es una lista y no un
+// map para que el orden de la salida del comando se conserve al pintar.
+type KeyValue []Pair
+
+// Metric es una medida numérica con nombre.
+type Metric struct {
+	Name  string  `json:"name"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+// Metrics es una lista ordenada de medidas.
+type Metrics []Metric
+
+// Node es un nodo de un árbol.
+type Node struct {
+	Label    string `json:"label"`
+	Children []Node `json:"children,omitempty"`
+}
+
+// Tree es una lista de nodos raíz.
+type Tree []Node
+
+func (Text) Kind() Kind     { return KindText }
+func (Lines) Kind() Kind    { return KindLines }
+func (Table) Kind() Kind    { return KindTable }
+func (KeyValue) Kind() Kind { return KindKeyValue }
+func (Metrics) Kind() Kind  { return KindMetrics }
+func (Tree) Kind() Kind     { return KindTree }
+
+// Envelope es la forma serializada de un Value: el tipo y los datos.
+type Envelope struct {
+	Kind Kind            `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Wrap serializa un Value en un Envelope.
+func Wrap(v Value) (Envelope, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return Envelope{}, err
+	}
+	return Envelope{Kind: v.Kind(), Data: raw}, nil
+}
+
+// Unwrap reconstruye el Value concreto de un Envelope.
+func (e Envelope) Unwrap() (Value, error) {
+	var v Value
+	switch e.Kind {
+	case KindText:
+		v = new(Text)
+	case KindLines:
+		v = new(Lines)
+	case KindTable:
+		v = new(Table)
+	case KindKeyValue:
+		v = new(KeyValue)
+	case KindMetrics:
+		v = new(Metrics)
+	case KindTree:
+		v = new(Tree)
+	default:
+		return nil, fmt.Errorf("tipo de datos desconocido: %q", e.Kind)
+	}
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return nil, fmt.Errorf("datos de tipo %s inválidos: %w", e.Kind, err)
+	}
+
+	// Devolvemos el valor, no el puntero, para que los type switch de los
+	// renderers vean el mismo tipo que devuelve el parser.
+	switch p := v.(type) {
+	case *Text:
+		return *p, nil
+	case *Lines:
+		return *p, nil
+	case *Table:
+		return *p, nil
+	case *KeyValue:
+		return *p, nil
+	case *Metrics:
+		return *p, nil
+	case *Tree:
+		return *p, nil
+	}
+	return v, nil
+}
+
+// AsLines convierte cualquier Value en líneas de texto, para los bloques
+// que trabajan con la salida de otros (filtros, contadores...).
+func AsLines(v Value) []string {
+	switch d := v.(type) {
+	case Text:
+		return strings.Split(strings.TrimRight(string(d), "\n"), "\n")
+	case Lines:
+		return d
+	case Table:
+		lines := make([]string, len(d))
+		for i, row := range d {
+			lines[i] = strings.Join(row, "\t")
+		}
+		return lines
+	case KeyValue:
+		lines := make([]string, len(d))
+		for i, p := range d {
+			lines[i] = p.Key + "=" + p.Value
+		}
+		return lines
+	case Metrics:
+		lines := make([]string, len(d))
+		for i, m := range d {
+			lines[i] = m.Name + "=" + strconv.FormatFloat(m.Value, 'f', -1, 64) + m.Unit
+		}
+		return lines
+	case Tree:
+		var lines []string
+		var walk func(nodes []Node, depth int)
+		walk = func(nodes []Node, depth int) {
+			for _, n := range nodes {
+				lines = append(lines, strings.Repeat("  ", depth)+n.Label)
+				walk(n.Children, depth+1)
+			}
+		}
+		walk(d, 0)
+		return lines
+	}
+	return nil
+}
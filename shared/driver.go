@@ -0,0 +1,219 @@
+// shared/driver.go
+package shared
+
+import (
+    "time"
+
+    "github.com/charmbracelet/bubbletea"
+    "github.com/gas/fancy-welcome/logging"
+    "github.com/gas/fancy-welcome/shared/block"
+)
+
+// settleDelay es el tiempo sin actividad tras el cual un bloque sin comandos
+// pendientes se da por terminado. Deja margen a los streams para volcar
+// sus primeras líneas.
+const settleDelay = 300 * time.Millisecond
+
+// defaultBlockTimeout se usa si ni el bloque ni la config general fijan uno.
+const defaultBlockTimeout = 5 * time.Second
+
+// driverResult es el mensaje producido por un comando, junto al bloque
+// que lo originó (owner). Solo los resultados 'tracked' salieron de un
+// comando contado como pendiente; el resto llega por Send.
+type driverResult struct {
+    owner   string
+    msg     tea.Msg
+    tracked bool
+}
+
+// Driver ejecuta el ciclo update/fetch de los bloques de forma síncrona,
+// sin un programa de Bubble Tea. Lo usan los modos --simple.
+type Driver struct {
+    blocks  []block.Block
+    results chan driverResult
+    done    chan struct{} // Se cierra al terminar Run
+
+    // OnMsg, si no es nil, ve cada mensaje antes de repartirlo. Permite
+    // capturar la salida de los bloques (TeeOutputMsg) fuera de la TUI.
+    OnMsg func(msg tea.Msg)
+
+    pending      map[string]int
+    lastActivity map[string]time.Time
+}
+
+// NewDriver crea un driver para los bloques dados.
+func NewDriver(blocks []block.Block) *Driver {
+    return &Driver{
+        blocks:       blocks,
+        results:      make(chan driverResult, 256),
+        done:         make(chan struct{}),
+        pending:      make(map[string]int),
+        lastActivity: make(map[string]time.Time),
+    }
+}
+
+// Send cumple block.Sender, para que los bloques en streaming puedan
+// inyectar mensajes igual que con un *tea.Program. Cuando Run ya ha
+// terminado, el mensaje se descarta.
+func (d *Driver) Send(msg tea.Msg) {
+    owner := ""
+    if targetMsg, ok := msg.(block.TargetedMsg); ok {
+        owner = targetMsg.BlockID()
+    }
+    d.deliver(driverResult{owner: owner, msg: msg})
+}
+
+// deliver entrega un resultado al bucle de Run. Nadie lo lee después de
+// que Run termine, así que entonces no espera: un stream que sigue
+// enviando no se queda bloqueado (ni bloquea su Stop) con el canal lleno.
+func (d *Driver) deliver(res driverResult) {
+    select {
+    case d.results <- res:
+    case <-d.done:
+    }
+}
+
+// Run lanza un TriggerUpdateMsg y procesa mensajes hasta que todos los
+// bloques terminan o agotan su timeout. timeoutFor devuelve la espera
+// máxima de cada bloque.
+func (d *Driver) Run(timeoutFor func(b block.Block) time.Duration) {
+    block.SetOneShot(true)
+    defer block.SetOneShot(false)
+    defer close(d.done)
+
+    start := time.Now()
+    deadlines := make(map[string]time.Time, len(d.blocks))
+    for _, b := range d.blocks {
+        if s, ok := b.(block.Streamer); ok {
+            s.SetProgram(d)
+        }
+        deadlines[b.Name()] = start.Add(timeoutFor(b))
+        d.lastActivity[b.Name()] = start
+    }
+
+    d.dispatch(block.TriggerUpdateMsg{})
+
+    ticker := time.NewTicker(50 * time.Millisecond)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case res := <-d.results:
+            if res.owner != "" {
+                d.lastActivity[res.owner] = time.Now()
+            }
+            if res.tracked {
+                d.pending[res.owner]--
+            }
+            // Los lotes heredan el dueño del comando que los produjo.
+            if batch, ok := res.msg.(tea.BatchMsg); ok {
+                for _, cmd := range batch {
+                    d.exec(res.owner, cmd)
+                }
+                continue
+            }
+            if res.msg != nil {
+                d.dispatch(res.msg)
+            }
+        case now := <-ticker.C:
+            if d.allDone(now, deadlines) {
+                return
+            }
+        }
+    }
+}
+
+// allDone indica si todos los bloques han terminado o agotado su timeout.
+func (d *Driver) allDone(now time.Time, deadlines map[string]time.Time) bool {
+    for _, b := range d.blocks {
+        name := b.Name()
+        if now.After(deadlines[name]) {
+            continue
+        }
+        if d.pending[name] > 0 || now.Sub(d.lastActivity[name]) < settleDelay {
+            return false
+        }
+    }
+    return true
+}
+
+// dispatch reparte un mensaje igual que los modelos TUI: los TargetedMsg
+// a su destinatario y el resto a todos.
+func (d *Driver) dispatch(msg tea.Msg) {
+    if d.OnMsg != nil {
+        d.OnMsg(msg)
+    }
+    // Sin TUI no hay dónde enseñar las notificaciones: van al log.
+    if note, ok := msg.(block.NotificationMsg); ok {
+        logging.Log.Printf("[%s] %s: %s", note.Source, note.Severity, note.Text)
+        return
+    }
+
+    if targetMsg, ok := msg.(block.TargetedMsg); ok {
+        targetID := targetMsg.BlockID()
+        for i, b := range d.blocks {
+            if b.Name() == targetID {
+                d.update(i, msg)
+                break
+            }
+        }
+        return
+    }
+
+    for i := range d.blocks {
+        d.update(i, msg)
+    }
+}
+
+func (d *Driver) update(i int, msg tea.Msg) {
+    updatedBlock, cmd := d.blocks[i].Update(msg)
+    d.blocks[i] = updatedBlock
+    d.exec(updatedBlock.Name(), cmd)
+}
+
+// exec ejecuta un comando en una goroutine y devuelve su mensaje al bucle.
+func (d *Driver) exec(owner string, cmd tea.Cmd) {
+    if cmd == nil {
+        return
+    }
+    tracked := owner != ""
+    if tracked {
+        d.pending[owner]++
+        d.lastActivity[owner] = time.Now()
+    }
+    go func() {
+        d.deliver(driverResult{owner: owner, msg: cmd(), tracked: tracked})
+    }()
+}
+
+// CloseBlocks libera los recursos de los bloques que lo necesiten.
+func CloseBlocks(blocks []block.Block) {
+    for _, b := range blocks {
+        if closer, ok := b.(block.Closer); ok {
+            if err := closer.Close(); err != nil {
+                logging.Log.Printf("[%s] Error closing block: %v", b.Name(), err)
+            }
+        }
+    }
+}
+
+// BlockTimeout devuelve la espera máxima de un bloque en modo --simple:
+// su clave 'timeout' o, si no la tiene, 'tty_timeout_seconds' de [general].
+func (r *SetupResult) BlockTimeout(b block.Block) time.Duration {
+    blockConfig, _ := r.Config.Blocks[b.Name()].(map[string]interface{})
+
+    var secs float64
+    switch v := blockConfig["timeout"].(type) {
+    case float64:
+        secs = v
+    case int64:
+        secs = float64(v)
+    }
+    if secs <= 0 {
+        secs = r.Config.General.TTYTimeoutSeconds
+    }
+    if secs <= 0 {
+        return defaultBlockTimeout
+    }
+    return time.Duration(secs * float64(time.Second))
+}
@@ -0,0 +1,85 @@
+// shared/focus.go
+package shared
+
+// Region es el rectángulo que ocupa un bloque en el dashboard, en celdas
+// desde la esquina de arriba a la izquierda. Index es su posición en la
+// lista de bloques, como el foco.
+type Region struct {
+    Index         int
+    X, Y          int
+    Width, Height int
+}
+
+// Direcciones para Neighbor.
+const (
+    Up = iota
+    Down
+    Left
+    Right
+)
+
+// RegionOf devuelve dónde quedó el bloque 'index' en el último Render.
+func (l *Layout) RegionOf(index int) (Region, bool) {
+    for _, r := range l.regions {
+        if r.Index == index {
+            return r, true
+        }
+    }
+    return Region{}, false
+}
+
+// BlockAt devuelve el bloque que quedó en la celda (x, y) del último
+// Render, o -1 si ahí no hay ninguno.
+func (l *Layout) BlockAt(x, y int) int {
+    for _, r := range l.regions {
+        if x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height {
+            return r.Index
+        }
+    }
+    return -1
+}
+
+// Neighbor devuelve el bloque más cercano a 'from' en una dirección, según
+// el último Render, o -1 si no hay ninguno. Manda la distancia en esa
+// dirección y, a igualdad, lo alineados que estén.
+func (l *Layout) Neighbor(from, direction int) int {
+    origin, ok := l.RegionOf(from)
+    if !ok {
+        return -1
+    }
+
+    best, bestGap, bestSkew := -1, 0, 0
+    for _, r := range l.regions {
+        if r.Index == from {
+            continue
+        }
+        var gap, skew int
+        switch direction {
+        case Up:
+            gap, skew = origin.Y-(r.Y+r.Height), abs(centerX(r)-centerX(origin))
+        case Down:
+            gap, skew = r.Y-(origin.Y+origin.Height), abs(centerX(r)-centerX(origin))
+        case Left:
+            gap, skew = origin.X-(r.X+r.Width), abs(centerY(r)-centerY(origin))
+        case Right:
+            gap, skew = r.X-(origin.X+origin.Width), abs(centerY(r)-centerY(origin))
+        }
+        if gap < 0 {
+            continue // No está en esa dirección
+        }
+        if best < 0 || gap < bestGap || (gap == bestGap && skew < bestSkew) {
+            best, bestGap, bestSkew = r.Index, gap, skew
+        }
+    }
+    return best
+}
+
+func centerX(r Region) int { return r.X + r.Width/2 }
+func centerY(r Region) int { return r.Y + r.Height/2 }
+
+func abs(n int) int {
+    if n < 0 {
+        return -n
+    }
+    return n
+}
@@ -0,0 +1,357 @@
+// shared/keys.go
+package shared
+
+import (
+    "fmt"
+    "slices"
+    "strings"
+    "unicode/utf8"
+
+    "github.com/charmbracelet/bubbles/help"
+    "github.com/charmbracelet/bubbles/key"
+    "github.com/charmbracelet/bubbles/viewport"
+    "github.com/charmbracelet/bubbletea"
+    "github.com/charmbracelet/lipgloss"
+
+    "github.com/gas/fancy-welcome/config"
+    "github.com/gas/fancy-welcome/shared/block"
+    "github.com/gas/fancy-welcome/themes"
+)
+
+// Estados de la TUI. Cada uno atiende sus propias teclas, así que una
+// tecla solo choca con las de su mismo estado.
+const (
+    keysDashboard = "dashboard"
+    keysExpanded  = "expanded"
+    keysInput     = "input" // Escribiendo un filtro
+)
+
+// keyAction es una acción con tecla configurable en [keys].
+type keyAction struct {
+    name   string
+    keys   []string // Teclas por defecto
+    label  string   // Cómo se ven las teclas por defecto en la ayuda
+    desc   string
+    states []string
+}
+
+var (
+    inDashboard = []string{keysDashboard}
+    inExpanded  = []string{keysExpanded}
+    inBoth      = []string{keysDashboard, keysExpanded}
+    inInput     = []string{keysInput}
+)
+
+// keyActions son todas las acciones, en el orden de la ayuda.
+var keyActions = []keyAction{
+    {"quit", []string{"q"}, "q", "salir", inDashboard},
+    {"help", []string{"?"}, "?", "ayuda", inBoth},
+    {"focus_next", []string{"tab"}, "tab", "foco siguiente", inDashboard},
+    {"focus_prev", []string{"shift+tab"}, "shift+tab", "foco anterior", inDashboard},
+    {"up", []string{"up"}, "↑", "arriba", inBoth},
+    {"down", []string{"down"}, "↓", "abajo", inBoth},
+    {"left", []string{"left"}, "←", "izquierda", inDashboard},
+    {"right", []string{"right"}, "→", "derecha", inDashboard},
+    {"scroll_down", []string{"j"}, "j", "desplazar abajo", inBoth},
+    {"scroll_up", []string{"k"}, "k", "desplazar arriba", inBoth},
+    {"page_down", []string{"pgdown"}, "pgdown", "pantalla abajo", inBoth},
+    {"page_up", []string{"pgup"}, "pgup", "pantalla arriba", inBoth},
+    {"top", []string{"home"}, "home", "al principio", inBoth},
+    {"bottom", []string{"end"}, "end", "al final", inBoth},
+    {"expand", []string{"enter"}, "enter", "expandir", inDashboard},
+    {"refresh", []string{"r"}, "r", "actualizar bloque", inDashboard},
+    {"back", []string{"esc", "q", "enter"}, "esc", "volver", inExpanded},
+    {"save", []string{"s"}, "s", "guardar", inExpanded},
+    {"copy", []string{"c"}, "c", "copiar", inExpanded},
+    {"search", []string{"/"}, "/", "buscar", inExpanded},
+    {"next_match", []string{"n"}, "n", "siguiente coincidencia", inExpanded},
+    {"prev_match", []string{"N"}, "N", "coincidencia anterior", inExpanded},
+    {"filter_lines", []string{"f"}, "f", "filtrar líneas", inExpanded},
+    {"follow", []string{"p"}, "p", "seguir/pausar", inExpanded},
+    {"theme", []string{"t"}, "t", "tema", inDashboard},
+    {"messages", []string{"m"}, "m", "mensajes", inBoth},
+    {"next_page", []string{"]"}, "]", "página siguiente", inDashboard},
+    {"prev_page", []string{"["}, "[", "página anterior", inDashboard},
+    {"add_filter", []string{"a"}, "a", "filtrar", inDashboard},
+    {"delete", []string{"d"}, "d", "borrar filtro", inDashboard},
+    {"save_layout", []string{"w"}, "w", "guardar layout", inDashboard},
+    {"confirm", []string{"enter"}, "enter", "aceptar", inInput},
+    {"cancel", []string{"esc"}, "esc", "cancelar", inInput},
+    {"toggle_colors", []string{"tab"}, "tab", "con/sin colores", inInput},
+}
+
+// PageKeys cambian de página (1-9); no se pueden cambiar en [keys].
+var PageKeys = key.NewBinding(
+    key.WithKeys("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+    key.WithHelp("1-9", "ir a la página"),
+)
+
+// keyNames son los nombres de tecla de Bubble Tea ("enter", "ctrl+s"...).
+var keyNames = func() map[string]bool {
+    names := make(map[string]bool)
+    for k := tea.KeyType(-200); k <= 127; k++ {
+        if name := k.String(); name != "" && k != tea.KeyRunes {
+            names[name] = true
+        }
+    }
+    return names
+}()
+
+// KeyMap son las teclas de la TUI: las de [keys] o, si no, las de por defecto.
+// ctrl+c sale siempre, esté donde esté.
+type KeyMap struct {
+    Quit, Help                    key.Binding
+    FocusNext, FocusPrev          key.Binding
+    Up, Down, Left, Right         key.Binding
+    ScrollDown, ScrollUp          key.Binding
+    PageDown, PageUp, Top, Bottom key.Binding
+    Expand, Back, Save, Copy      key.Binding
+    Refresh                       key.Binding
+    Search, NextMatch, PrevMatch  key.Binding
+    FilterLines, Follow           key.Binding
+    Theme, Messages               key.Binding
+    NextPage, PrevPage            key.Binding
+    AddFilter, Delete, SaveLayout key.Binding
+    Confirm, Cancel, ToggleColors key.Binding
+}
+
+// NewKeyMap construye las teclas de la config. Los problemas de [keys] ya
+// los avisa Validate: aquí se usa lo que quede válido.
+func NewKeyMap(cfg *config.Config) *KeyMap {
+    keys, _ := resolveKeys(cfg)
+    km := &KeyMap{}
+    bindings := map[string]*key.Binding{
+        "quit": &km.Quit, "help": &km.Help,
+        "focus_next": &km.FocusNext, "focus_prev": &km.FocusPrev,
+        "up": &km.Up, "down": &km.Down, "left": &km.Left, "right": &km.Right,
+        "scroll_down": &km.ScrollDown, "scroll_up": &km.ScrollUp,
+        "page_down": &km.PageDown, "page_up": &km.PageUp, "top": &km.Top, "bottom": &km.Bottom,
+        "expand": &km.Expand, "refresh": &km.Refresh, "back": &km.Back, "save": &km.Save, "copy": &km.Copy,
+        "search": &km.Search, "next_match": &km.NextMatch, "prev_match": &km.PrevMatch,
+        "filter_lines": &km.FilterLines, "follow": &km.Follow,
+        "theme": &km.Theme, "messages": &km.Messages, "next_page": &km.NextPage, "prev_page": &km.PrevPage,
+        "add_filter": &km.AddFilter, "delete": &km.Delete, "save_layout": &km.SaveLayout,
+        "confirm": &km.Confirm, "cancel": &km.Cancel, "toggle_colors": &km.ToggleColors,
+    }
+    for _, action := range keyActions {
+        label := action.label
+        if !slices.Equal(keys[action.name], action.keys) {
+            label = strings.Join(keys[action.name], "/")
+        }
+        binding := key.NewBinding(key.WithKeys(keys[action.name]...), key.WithHelp(label, action.desc))
+        // Una acción sin teclas no se atiende ni sale en la ayuda.
+        binding.SetEnabled(len(keys[action.name]) > 0)
+        *bindings[action.name] = binding
+    }
+    return km
+}
+
+// resolveKeys devuelve las teclas de cada acción según [keys] y los
+// problemas encontrados: acciones desconocidas, teclas que no existen y
+// teclas repetidas en un mismo estado. En un choque la tecla se la queda
+// la acción que la pone en [keys] (y, entre varias, la primera de la
+// ayuda); las demás la pierden.
+func resolveKeys(cfg *config.Config) (map[string][]string, []Problem) {
+    var problems []Problem
+    warn := func(name, message string) {
+        problems = append(problems, Problem{
+            File:    cfg.Source(),
+            Line:    cfg.Line("keys", name),
+            Key:     "keys." + name,
+            Message: message,
+            Warning: true,
+        })
+    }
+
+    names := make([]string, len(keyActions))
+    for i, action := range keyActions {
+        names[i] = action.name
+    }
+    for _, name := range sortedKeys(cfg.Keys) {
+        if !slices.Contains(names, name) {
+            warn(name, fmt.Sprintf("acción desconocida%s; se ignora", block.Suggestion(name, names)))
+        }
+    }
+
+    keys := make(map[string][]string, len(keyActions))
+    custom := make(map[string]bool)
+    for _, action := range keyActions {
+        keys[action.name] = action.keys
+        value, ok := cfg.Keys[action.name]
+        if !ok {
+            continue
+        }
+        parsed, err := parseKeys(value)
+        if err != nil {
+            warn(action.name, err.Error()+"; se usan las de por defecto")
+            continue
+        }
+        if slices.Contains(parsed, "ctrl+c") {
+            warn(action.name, "ctrl+c siempre sale; se ignora")
+            parsed = slices.DeleteFunc(parsed, func(k string) bool { return k == "ctrl+c" })
+        }
+        keys[action.name], custom[action.name] = parsed, true
+    }
+
+    for _, state := range []string{keysDashboard, keysExpanded, keysInput} {
+        owner := make(map[string]string) // Tecla -> acción que se la queda
+        if state == keysDashboard {
+            for _, k := range PageKeys.Keys() {
+                owner[k] = "páginas"
+            }
+        }
+        // Primero reparten las acciones de [keys] y luego las de por defecto.
+        for _, fromConfig := range []bool{true, false} {
+            for _, action := range keyActions {
+                if custom[action.name] != fromConfig || !slices.Contains(action.states, state) {
+                    continue
+                }
+                var kept []string
+                for _, k := range keys[action.name] {
+                    if other, taken := owner[k]; taken && other != action.name {
+                        warn(action.name, fmt.Sprintf("la tecla %q ya es de '%s'; aquí se ignora", k, other))
+                        continue
+                    }
+                    owner[k] = action.name
+                    kept = append(kept, k)
+                }
+                keys[action.name] = kept
+            }
+        }
+    }
+    return keys, problems
+}
+
+// parseKeys lee el valor de una acción de [keys]: una tecla o una lista.
+func parseKeys(value interface{}) ([]string, error) {
+    var raw []interface{}
+    switch v := value.(type) {
+    case string:
+        if v == "" {
+            return []string{}, nil
+        }
+        raw = []interface{}{v}
+    case []interface{}:
+        raw = v
+    default:
+        return nil, fmt.Errorf("debe ser una tecla o una lista de teclas")
+    }
+
+    keys := []string{}
+    for _, item := range raw {
+        k, ok := item.(string)
+        if !ok || k == "" {
+            return nil, fmt.Errorf("debe ser una tecla o una lista de teclas")
+        }
+        if !validKey(k) {
+            return nil, fmt.Errorf("tecla %q desconocida", k)
+        }
+        keys = append(keys, k)
+    }
+    return keys, nil
+}
+
+// validKey indica si 'k' es una tecla que Bubble Tea puede enviar: un
+// carácter o un nombre como "enter" o "ctrl+s", con o sin "alt+".
+func validKey(k string) bool {
+    k = strings.TrimPrefix(k, "alt+")
+    return utf8.RuneCountInString(k) == 1 || keyNames[k]
+}
+
+// DashboardHelp agrupa en columnas las teclas del dashboard. 'pages' añade
+// las de las páginas y 'filter' las del modo filter.
+func (km *KeyMap) DashboardHelp(pages, filter bool) [][]key.Binding {
+    actions := []key.Binding{km.Expand, km.Refresh, km.Theme, km.Messages}
+    if pages {
+        actions = append(actions, PageKeys, km.NextPage, km.PrevPage)
+    }
+    if filter {
+        actions = append(actions, km.AddFilter, km.Delete, km.SaveLayout)
+    }
+    return [][]key.Binding{
+        {km.FocusNext, km.FocusPrev, km.Up, km.Down, km.Left, km.Right},
+        {km.ScrollDown, km.ScrollUp, km.PageDown, km.PageUp, km.Top, km.Bottom},
+        append(actions, km.Help, km.Quit),
+    }
+}
+
+// ExpandedHelp agrupa en columnas las teclas de la vista expandida. 'save'
+// añade la de guardarla y 'follow' la de seguir el final (un stream).
+func (km *KeyMap) ExpandedHelp(save, follow bool) [][]key.Binding {
+    actions := []key.Binding{km.Back}
+    if save {
+        actions = append(actions, km.Save)
+    }
+    scroll := []key.Binding{km.Up, km.Down, km.ScrollDown, km.ScrollUp, km.PageDown, km.PageUp, km.Top, km.Bottom}
+    if follow {
+        scroll = append(scroll, km.Follow)
+    }
+    return [][]key.Binding{
+        scroll,
+        {km.Search, km.NextMatch, km.PrevMatch, km.FilterLines},
+        append(actions, km.Copy, km.Messages, km.Help),
+    }
+}
+
+// BlockKeys son las teclas que atienden los propios bloques con el foco.
+func (km *KeyMap) BlockKeys() block.Keys {
+    return block.Keys{Refresh: km.Refresh}
+}
+
+// BindKeys pasa a los bloques que las usan (block.KeyBinder) sus teclas.
+func BindKeys(blocks []block.Block, km *KeyMap) {
+    for _, b := range blocks {
+        if binder, ok := b.(block.KeyBinder); ok {
+            binder.SetKeys(km.BlockKeys())
+        }
+    }
+}
+
+// InputHelp son las teclas mientras se escribe (un filtro, una búsqueda...).
+func (km *KeyMap) InputHelp() []key.Binding {
+    return []key.Binding{km.Confirm, km.Cancel}
+}
+
+// ViewportKeys son las teclas de desplazamiento del viewport: las flechas
+// y las de desplazar del KeyMap (la media página sigue siendo u/d).
+func (km *KeyMap) ViewportKeys() viewport.KeyMap {
+    keys := viewport.DefaultKeyMap()
+    keys.Up = key.NewBinding(key.WithKeys(append(km.Up.Keys(), km.ScrollUp.Keys()...)...))
+    keys.Down = key.NewBinding(key.WithKeys(append(km.Down.Keys(), km.ScrollDown.Keys()...)...))
+    keys.PageUp, keys.PageDown = km.PageUp, km.PageDown
+    keys.Left, keys.Right = km.Left, km.Right
+    return keys
+}
+
+// KeyHints resume unas teclas en una línea ("tab foco siguiente · q salir")
+// para la barra de estado. Las desactivadas no salen.
+func KeyHints(bindings ...key.Binding) string {
+    var hints []string
+    for _, binding := range bindings {
+        if binding.Enabled() {
+            hints = append(hints, binding.Help().Key+" "+binding.Help().Desc)
+        }
+    }
+    return strings.Join(hints, " · ")
+}
+
+// RenderHelp compone la ayuda de teclas ('?'): las columnas de 'groups' en
+// un recuadro centrado en un área de width x height.
+func RenderHelp(width, height int, styles *themes.StyleSheet, groups [][]key.Binding) string {
+    model := help.New()
+    model.ShowAll = true
+    model.Styles.FullKey = styles.Primary.Bold(true)
+    model.Styles.FullDesc = styles.Base
+    model.Styles.FullSeparator = styles.Muted
+    model.Styles.Ellipsis = styles.Muted
+
+    content := lipgloss.JoinVertical(lipgloss.Left,
+        styles.Title.Render(" Teclas "),
+        "",
+        model.FullHelpView(groups),
+        "",
+        styles.Muted.Render("cualquier tecla para cerrar"),
+    )
+    box := styles.FocusedBorder.Padding(0, 1).Render(content)
+    return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
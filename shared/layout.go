@@ -2,88 +2,706 @@
 package shared
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
-    "github.com/gas/fancy-welcome/logging"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/gas/fancy-welcome/config"
 	"github.com/gas/fancy-welcome/shared/block"
+	"github.com/gas/fancy-welcome/themes"
 )
 
-// RenderDashboard compone la vista de todos los bloques en un solo string.
-// Recibe todo lo que necesita para renderizar como argumentos.
-func RenderDashboard(
-	width int,
-	blocks []block.Block,
-	focusIndex int,
-	normalStyle, focusStyle lipgloss.Style,
-) string {
-	if width == 0 {
-		return "Initializing..."
+// DefaultLayout es el preset que se usa si la config no elige ninguno: dos
+// columnas iguales, como el antiguo reparto left/right.
+const DefaultLayout = "columns"
+
+// Modos de 'overflow' de un bloque con 'max_height'.
+const (
+	OverflowTruncate = "truncate" // Las primeras líneas y cuántas faltan
+	OverflowScroll   = "scroll"   // Una ventana que sigue al final y se puede desplazar
+)
+
+// Prioridades de un bloque ('priority'). Un breakpoint con 'min_priority'
+// oculta los bloques de prioridad menor.
+var priorities = map[string]int{"low": 0, "normal": 1, "high": 2}
+
+// DefaultPriority es la prioridad de los bloques que no la indican.
+const DefaultPriority = "normal"
+
+// builtinLayouts son los presets integrados. Los de [layouts] de la config
+// los amplían o los sustituyen.
+var builtinLayouts = map[string][]interface{}{
+	"columns": {"1fr", "1fr"},
+	"single":  {"1fr"},
+	"three":   {"1fr", "1fr", "1fr"},
+	"sidebar": {int64(32), "1fr"},
+}
+
+// Track es el ancho de una columna: fijo (en celdas) o una fracción del
+// sitio que dejan las fijas.
+type Track struct {
+	Fixed    int
+	Fraction float64
+}
+
+// ParseTrack lee una columna de un preset: un entero (ancho fijo) o un
+// string "<n>fr".
+func ParseTrack(value interface{}) (Track, error) {
+	switch v := value.(type) {
+	case int64:
+		if v > 0 {
+			return Track{Fixed: int(v)}, nil
+		}
+	case string:
+		if number, isFraction := strings.CutSuffix(v, "fr"); isFraction {
+			if n, err := strconv.ParseFloat(number, 64); err == nil && n > 0 {
+				return Track{Fraction: n}, nil
+			}
+		}
+	}
+	return Track{}, fmt.Errorf("columna %#v no válida: usa un ancho fijo (30) o una fracción (\"1fr\")", value)
+}
+
+// Placement es dónde y cómo se coloca un bloque en la rejilla: las claves
+// 'column', 'span', 'row_span', 'min_height', 'max_height' y 'overflow' de
+// su config. Un cero es "automático" o "sin límite".
+type Placement struct {
+	Column    int // 1..N; 0 = el siguiente hueco libre
+	Span      int // Columnas que ocupa
+	RowSpan   int // Filas que ocupa
+	MinHeight int // Alto mínimo, con el marco
+	MaxHeight int // Alto máximo, con el marco
+	Overflow  string
+	Priority  int // Valor de 'priorities'
+}
+
+// placementFrom lee la colocación de la config de un bloque.
+func placementFrom(blockConfig map[string]interface{}) Placement {
+	number := func(key string) int {
+		switch n := blockConfig[key].(type) {
+		case int64:
+			return int(n)
+		case float64:
+			return int(n)
+		}
+		return 0
+	}
+	p := Placement{
+		Column:    number("column"),
+		Span:      number("span"),
+		RowSpan:   number("row_span"),
+		MinHeight: number("min_height"),
+		MaxHeight: number("max_height"),
+	}
+	p.Overflow, _ = blockConfig["overflow"].(string)
+
+	priority, _ := blockConfig["priority"].(string)
+	if _, ok := priorities[priority]; !ok {
+		priority = DefaultPriority
+	}
+	p.Priority = priorities[priority]
+	return p
+}
+
+// breakpoint es una regla [[breakpoints]] ya resuelta.
+type breakpoint struct {
+	below       int
+	columns     []Track // nil = las del layout de [general]
+	minPriority int
+}
+
+// Layout coloca los bloques en una rejilla de N columnas. Sale de la config
+// (el preset elegido, los breakpoints y la colocación de cada bloque) y
+// guarda cuánto se ha desplazado cada bloque con overflow = "scroll".
+type Layout struct {
+	Name        string
+	columns     []Track
+	breakpoints []breakpoint // De más ancho a más estrecho
+	placements  map[string]Placement
+	members     map[string]int // Bloques que se muestran y su orden; nil = todos
+	offsets     map[string]int // Líneas desplazadas, ver ScrollBy
+	clipped     map[string]bool // Bloques a los que no les cabe todo el contenido
+	regions     []Region        // Dónde quedó cada bloque en el último Render
+}
+
+// NewLayout construye el layout de la config. Un preset desconocido o mal
+// escrito no impide arrancar: se usa el integrado (Validate ya avisa).
+func NewLayout(cfg *config.Config) *Layout {
+	return newLayout(cfg, cfg.General.Layout, nil)
+}
+
+// newLayout construye un layout con el preset 'name' ("" = el integrado).
+// Con 'members', solo muestra esos bloques y en ese orden (los de una página).
+func newLayout(cfg *config.Config, name string, members []string) *Layout {
+	if name == "" {
+		name = DefaultLayout
+	}
+	columns, err := layoutColumns(cfg, name)
+	if err != nil {
+		name = DefaultLayout
+		columns, _ = layoutColumns(cfg, name)
+	}
+
+	var ranks map[string]int
+	if members != nil {
+		ranks = make(map[string]int, len(members))
+		for i, member := range members {
+			if _, seen := ranks[member]; !seen {
+				ranks[member] = i
+			}
+		}
+	}
+
+	placements := make(map[string]Placement, len(cfg.Blocks))
+	for blockName, raw := range cfg.Blocks {
+		if blockConfig, ok := raw.(map[string]interface{}); ok {
+			placements[blockName] = placementFrom(blockConfig)
+		}
+	}
+
+	// Las reglas mal escritas se ignoran en lo que tengan mal (Validate avisa).
+	var breakpoints []breakpoint
+	for _, rule := range cfg.Breakpoints {
+		if rule.Below <= 0 {
+			continue
+		}
+		bp := breakpoint{below: rule.Below, minPriority: priorities[rule.MinPriority]}
+		if rule.Layout != "" {
+			bp.columns, _ = layoutColumns(cfg, rule.Layout)
+		}
+		breakpoints = append(breakpoints, bp)
+	}
+	sort.SliceStable(breakpoints, func(i, j int) bool { return breakpoints[i].below > breakpoints[j].below })
+
+	return &Layout{
+		Name:        name,
+		columns:     columns,
+		breakpoints: breakpoints,
+		placements:  placements,
+		members:     ranks,
+		offsets:     make(map[string]int),
+		clipped:     make(map[string]bool),
+	}
+}
+
+// at devuelve las columnas y la prioridad mínima para un ancho de terminal:
+// se aplican todos los breakpoints por debajo de los que está, y el más
+// estrecho manda.
+func (l *Layout) at(width int) ([]Track, int) {
+	columns, minPriority := l.columns, 0
+	for _, bp := range l.breakpoints {
+		if width >= bp.below {
+			continue
+		}
+		if bp.columns != nil {
+			columns = bp.columns
+		}
+		minPriority = max(minPriority, bp.minPriority)
+	}
+	return columns, minPriority
+}
+
+// Visible indica si un bloque se muestra con la terminal de 'width'
+// columnas: si es del layout (de su página) y no lo oculta un breakpoint
+// por su prioridad.
+func (l *Layout) Visible(width int, b block.Block) bool {
+	if _, member := l.members[b.Name()]; l.members != nil && !member {
+		return false
+	}
+	_, minPriority := l.at(width)
+	return l.placement(b).Priority >= minPriority
+}
+
+// visibleOrder devuelve los índices de los bloques visibles, en el orden
+// en que se colocan.
+func (l *Layout) visibleOrder(width int, blocks []block.Block) []int {
+	var order []int
+	for i, b := range blocks {
+		if l.Visible(width, b) {
+			order = append(order, i)
+		}
+	}
+	if l.members != nil {
+		sort.SliceStable(order, func(i, j int) bool {
+			return l.members[blocks[order[i]].Name()] < l.members[blocks[order[j]].Name()]
+		})
+	}
+	return order
+}
+
+// NextVisible devuelve el índice del bloque visible que va después de
+// 'from', dando la vuelta al final; si 'from' no se ve, el primero. Si no
+// se ve ninguno, devuelve 'from'.
+func (l *Layout) NextVisible(width int, blocks []block.Block, from int) int {
+	return l.stepVisible(width, blocks, from, 1)
+}
+
+// PrevVisible es como NextVisible, hacia atrás.
+func (l *Layout) PrevVisible(width int, blocks []block.Block, from int) int {
+	return l.stepVisible(width, blocks, from, -1)
+}
+
+func (l *Layout) stepVisible(width int, blocks []block.Block, from, step int) int {
+	order := l.visibleOrder(width, blocks)
+	if len(order) == 0 {
+		return from
+	}
+	for i, index := range order {
+		if index == from {
+			return order[(i+step+len(order))%len(order)]
+		}
+	}
+	return order[0]
+}
+
+// Resize aplica los breakpoints a un ancho de terminal nuevo y pasa a cada
+// bloque que lo admita (block.Sizer) el ancho de dentro de su marco.
+// 'border' es el marco por defecto, como en Render.
+func (l *Layout) Resize(width int, blocks []block.Block, border lipgloss.Style) {
+	for _, c := range l.arrange(width, blocks) {
+		sizer, ok := c.block.(block.Sizer)
+		if !ok {
+			continue
+		}
+		if c.block.RendererName() == "preformatted_text" {
+			sizer.SetWidth(c.width)
+			continue
+		}
+		frame := border
+		if styler, ok := c.block.(block.Styler); ok && styler.Styles() != nil {
+			frame = styler.Styles().Border
+		}
+		sizer.SetWidth(innerWidth(c.width, frame))
+	}
+}
+
+// layoutColumns devuelve las columnas del preset 'name': el de [layouts]
+// si existe, si no el integrado.
+func layoutColumns(cfg *config.Config, name string) ([]Track, error) {
+	specs, ok := builtinLayouts[name]
+	if preset, defined := cfg.Layouts[name]; defined {
+		specs, ok = preset.Columns, true
+	}
+	if !ok {
+		return nil, fmt.Errorf("layout %q desconocido%s (válidos: %s)", name, block.Suggestion(name, LayoutNames(cfg)), strings.Join(LayoutNames(cfg), ", "))
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("el layout %q no tiene columnas", name)
+	}
+	tracks := make([]Track, 0, len(specs))
+	for _, spec := range specs {
+		track, err := ParseTrack(spec)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, track)
+	}
+	return tracks, nil
+}
+
+// LayoutNames devuelve los presets disponibles: los integrados y los de la config.
+func LayoutNames(cfg *config.Config) []string {
+	var names []string
+	for name := range builtinLayouts {
+		names = append(names, name)
+	}
+	for name := range cfg.Layouts {
+		if _, builtin := builtinLayouts[name]; !builtin {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ScrollBy desplaza el contenido de un bloque recortado por 'max_height'
+// 'delta' líneas hacia el final (positivo) o hacia el principio (negativo).
+// Devuelve false si el bloque no tiene nada que desplazar (le cabe todo),
+// para que la tecla o la rueda desplacen el dashboard.
+func (l *Layout) ScrollBy(blockName string, delta int) bool {
+	if !l.clipped[blockName] {
+		return false
+	}
+	// Con "scroll" el desplazamiento se cuenta desde el final, que es lo
+	// que se sigue; con "truncate", desde el principio.
+	if l.placements[blockName].Overflow == OverflowScroll {
+		delta = -delta
+	}
+	l.offsets[blockName] = max(l.offsets[blockName]+delta, 0)
+	return true
+}
+
+// columnWidths reparte 'width' entre las columnas: primero las fijas y lo
+// que queda entre las fraccionarias, en proporción.
+func columnWidths(columns []Track, width int) []int {
+	widths := make([]int, len(columns))
+	remaining := width
+	var fractions float64
+	lastFraction := -1
+	for i, track := range columns {
+		if track.Fraction > 0 {
+			fractions += track.Fraction
+			lastFraction = i
+			continue
+		}
+		widths[i] = track.Fixed
+		remaining -= track.Fixed
 	}
+	remaining = max(remaining, 0)
 
-	var finalLayout []string
-	var leftColumnViews []string
-	var rightColumnViews []string
+	used := 0
+	for i, track := range columns {
+		if track.Fraction > 0 {
+			widths[i] = int(float64(remaining) * track.Fraction / fractions)
+			used += widths[i]
+		}
+	}
+	// Lo que sobra al redondear va a la última columna fraccionaria.
+	if lastFraction >= 0 {
+		widths[lastFraction] += remaining - used
+	}
+	return widths
+}
 
-	processPendingColumns := func() {
-        if len(leftColumnViews) > 0 || len(rightColumnViews) > 0 {
-            leftColumn := lipgloss.JoinVertical(lipgloss.Left, leftColumnViews...)
-            rightColumn := lipgloss.JoinVertical(lipgloss.Left, rightColumnViews...)
-            
-            // Une las columnas horizontalmente
-            joinedCols := lipgloss.JoinHorizontal(lipgloss.Top, leftColumn, rightColumn)
-            finalLayout = append(finalLayout, joinedCols)
+// cell es un bloque ya colocado en la rejilla.
+type cell struct {
+	index     int // Posición en la lista de bloques, para el foco
+	block     block.Block
+	placement Placement
+	row, col  int
+	x, width  int
+	height    int // Alto natural, con el marco
+}
 
-            // Limpia los slices para la siguiente sección de columnas
-            leftColumnViews = []string{}
-            rightColumnViews = []string{}
-        }
+// place asigna fila y columna a cada bloque, en orden. Los que fijan
+// 'column' buscan hueco en esa columna desde el principio de la sección
+// (lo que hay tras el último bloque que ocupa todo el ancho), como hacían
+// left y right; el resto ocupa el siguiente hueco en el que quepa.
+func (l *Layout) place(blocks []block.Block, columns int) []cell {
+	occupied := make(map[[2]int]bool)
+	fits := func(row, col int, p Placement) bool {
+		if col+p.Span > columns {
+			return false
+		}
+		for r := row; r < row+p.RowSpan; r++ {
+			for c := col; c < col+p.Span; c++ {
+				if occupied[[2]int{r, c}] {
+					return false
+				}
+			}
+		}
+		return true
 	}
 
+	cells := make([]cell, 0, len(blocks))
+	cursorRow, cursorCol, sectionStart := 0, 0, 0
 	for i, b := range blocks {
-		blockView := b.View()
-		var renderedBlock string
-
-        // --- LÓGICA DE RENDERIZADO CONDICIONAL PARA TEXTO CRUDO---
-        // Ya que hay casos de texto que vienen con color (neofetch, etc) y no queremos
-        // que se le aplique el style ya que lo pone blanco.
-        // Tiene que haber una mejor forma de hacerlo... porque así ni le pone el borde
-        if b.RendererName() == "preformatted_text" {
-            // Si es pre-formateado, usamos la vista cruda, sin bordes ni estilos.
-            renderedBlock = blockView
-            logging.Log.Printf("PREFORMATTED: blockIndex=%d, blockName=%s", i, b.Name())
-
-        } else {
-
-			var borderStyle lipgloss.Style
-
-			if i == focusIndex {
-				borderStyle = focusStyle
-			} else {
-				borderStyle = normalStyle
+		p := l.placementOf(b, columns)
+
+		row, col := cursorRow, cursorCol
+		if p.Column > 0 {
+			row, col = sectionStart, p.Column-1
+			for !fits(row, col, p) {
+				row++
+			}
+		} else {
+			for !fits(row, col, p) {
+				if col++; col+p.Span > columns {
+					row, col = row+1, 0
+				}
 			}
+		}
+
+		for r := row; r < row+p.RowSpan; r++ {
+			for c := col; c < col+p.Span; c++ {
+				occupied[[2]int{r, c}] = true
+			}
+		}
+		cells = append(cells, cell{index: i, block: b, placement: p, row: row, col: col})
+
+		if p.Span == columns {
+			sectionStart = row + p.RowSpan
+		}
+		if p.Column == 0 || p.Span == columns {
+			cursorRow, cursorCol = row, col+p.Span
+		}
+	}
+	return cells
+}
+
+// placement es la colocación de un bloque según la config. Los que no
+// están en ella (los creados en caliente) tienen la de por defecto.
+func (l *Layout) placement(b block.Block) Placement {
+	if p, ok := l.placements[b.Name()]; ok {
+		return p
+	}
+	return placementFrom(nil)
+}
+
+// placementOf es la colocación de un bloque ajustada a la rejilla. Sin
+// 'column' ni 'span' manda su 'position': left es la primera columna,
+// right la última y full-width (o nada) todo el ancho.
+func (l *Layout) placementOf(b block.Block, columns int) Placement {
+	p := l.placement(b)
+	if p.Column == 0 && p.Span == 0 {
+		switch b.Position() {
+		case "left":
+			p.Column = 1
+		case "right":
+			p.Column = columns
+		default:
+			p.Span = columns
+		}
+	}
+	p.Column = min(p.Column, columns)
+	p.Span = min(max(p.Span, 1), columns)
+	if p.Column > 0 {
+		p.Span = min(p.Span, columns-p.Column+1)
+	}
+	p.RowSpan = max(p.RowSpan, 1)
+	return p
+}
+
+// arrange coloca en la rejilla los bloques visibles con la terminal de
+// 'width' columnas, con su posición y su ancho en celdas.
+func (l *Layout) arrange(width int, blocks []block.Block) []cell {
+	columns, _ := l.at(width)
+
+	indexes := l.visibleOrder(width, blocks)
+	shown := make([]block.Block, len(indexes))
+	for i, index := range indexes {
+		shown[i] = blocks[index]
+	}
+
+	widths := columnWidths(columns, width)
+	columnX := make([]int, len(widths)+1)
+	for i, w := range widths {
+		columnX[i+1] = columnX[i] + w
+	}
+
+	cells := l.place(shown, len(widths))
+	for i := range cells {
+		c := &cells[i]
+		c.index = indexes[c.index]
+		c.x = columnX[c.col]
+		c.width = columnX[c.col+c.placement.Span] - c.x
+	}
+	return cells
+}
+
+// Render compone la vista de todos los bloques en un solo string de
+// 'width' celdas de ancho. normalStyle y focusStyle son los marcos por
+// defecto; los bloques con estilos propios (block.Styler) usan los suyos.
+func (l *Layout) Render(width int, blocks []block.Block, focusIndex int, normalStyle, focusStyle lipgloss.Style) string {
+	if width == 0 {
+		return "Initializing..."
+	}
+	cells := l.arrange(width, blocks)
+	if len(cells) == 0 {
+		return ""
+	}
+
+	// Primera pasada: el alto natural de cada bloque decide el de sus filas.
+	rows := 0
+	for i := range cells {
+		c := &cells[i]
+		c.height = lipgloss.Height(l.renderCell(*c, focusIndex, normalStyle, focusStyle, 0))
+		rows = max(rows, c.row+c.placement.RowSpan)
+	}
 
-			position := b.Position()
-			if position == "left" || position == "right" {
-				blockWidth := (width / 2) - 4
-				renderedBlock = borderStyle.Width(blockWidth).Render(blockView)
-			} else {
-				blockWidth := width - 2
-				renderedBlock = borderStyle.Width(blockWidth).Render(blockView)
+	// Los bloques de una fila van primero; los que ocupan varias solo
+	// agrandan la última que cubren, y solo si no caben.
+	bySpan := append([]cell{}, cells...)
+	sort.SliceStable(bySpan, func(i, j int) bool { return bySpan[i].placement.RowSpan < bySpan[j].placement.RowSpan })
+	rowHeights := make([]int, rows)
+	for _, c := range bySpan {
+		last := c.row + c.placement.RowSpan - 1
+		spanned := 0
+		for r := c.row; r < last; r++ {
+			spanned += rowHeights[r]
+		}
+		rowHeights[last] = max(rowHeights[last], c.height-spanned)
+	}
+	rowY := make([]int, rows+1)
+	for i, h := range rowHeights {
+		rowY[i+1] = rowY[i] + h
+	}
+
+	// Segunda pasada: cada bloque se estira hasta el alto de sus filas y se
+	// pinta en su sitio del lienzo.
+	type segment struct {
+		x    int
+		text string
+	}
+	canvas := make([][]segment, rowY[rows])
+	l.regions = l.regions[:0]
+	for _, c := range cells {
+		top := rowY[c.row]
+		height := rowY[c.row+c.placement.RowSpan] - top
+		l.regions = append(l.regions, Region{Index: c.index, X: c.x, Y: top, Width: c.width, Height: height})
+		lines := strings.Split(l.renderCell(c, focusIndex, normalStyle, focusStyle, height), "\n")
+		for i := 0; i < height; i++ {
+			line := ""
+			if i < len(lines) {
+				line = lines[i]
 			}
+			canvas[top+i] = append(canvas[top+i], segment{x: c.x, text: fitWidth(line, c.width)})
+		}
+	}
+
+	out := make([]string, len(canvas))
+	for y, segments := range canvas {
+		sort.Slice(segments, func(i, j int) bool { return segments[i].x < segments[j].x })
+		var builder strings.Builder
+		x := 0
+		for _, s := range segments {
+			builder.WriteString(strings.Repeat(" ", max(s.x-x, 0)))
+			builder.WriteString(s.text)
+			x = s.x + lipgloss.Width(s.text)
+		}
+		out[y] = strings.TrimRight(builder.String(), " ")
+	}
+	return strings.Join(out, "\n")
+}
+
+// renderCell dibuja un bloque al ancho de su celda. Con 'height' > 0 el
+// marco se estira hasta ese alto; con 0 sale con su alto natural (con
+// min_height y max_height aplicados).
+func (l *Layout) renderCell(c cell, focusIndex int, normalStyle, focusStyle lipgloss.Style, height int) string {
+	b := c.block
+	p := c.placement
+	target := height
+	if target == 0 {
+		target = p.MinHeight
+	}
+
+	// --- LÓGICA DE RENDERIZADO CONDICIONAL PARA TEXTO CRUDO---
+	// Hay textos que vienen con color (neofetch, etc) y no queremos que se
+	// les aplique el style, que los pone en blanco. Van tal cual, sin marco;
+	// el ancho lo recorta el lienzo.
+	if b.RendererName() == "preformatted_text" {
+		content := l.clip(b.Name(), b.View(), p.MaxHeight, p.Overflow, lipgloss.NewStyle().Faint(true))
+		return padHeight(content, target)
+	}
+
+	normal, focus := normalStyle, focusStyle
+	muted := lipgloss.NewStyle().Faint(true)
+	var styles *themes.StyleSheet
+	if styler, ok := b.(block.Styler); ok && styler.Styles() != nil {
+		styles = styler.Styles()
+		normal, focus = styles.Border, styles.FocusedBorder
+		muted = styles.Muted
+	}
+	border := normal
+	if c.index == focusIndex {
+		border = focus
+	}
+
+	// Título, marca de error y pie van en el propio marco.
+	var status block.Status
+	if reporter, ok := b.(block.Reporter); ok {
+		status = reporter.Status()
+	}
+
+	// Width incluye el padding pero no el borde; el contenido se parte al
+	// ancho que queda dentro.
+	border = border.Width(max(c.width-border.GetHorizontalBorderSize(), 1))
+	content := lipgloss.NewStyle().Width(innerWidth(c.width, border)).Render(b.View())
+	if p.MaxHeight > 0 {
+		// Sin borde, el título y el pie ocupan sus propias líneas.
+		limit := p.MaxHeight - border.GetVerticalFrameSize() - chromeLines(border, status)
+		content = l.clip(b.Name(), content, limit, p.Overflow, muted)
+	}
+
+	framed := frameBlock(content, border, styles, status)
+	if extra := target - lipgloss.Height(framed); extra > 0 {
+		inner := lipgloss.Height(border.Render(content)) - border.GetVerticalBorderSize()
+		framed = frameBlock(content, border.Height(inner+extra), styles, status)
+	}
+	return framed
+}
+
+// clip deja 'content' en 'limit' líneas como mucho. Con overflow "scroll"
+// muestra una ventana que sigue el final y, si no, las primeras líneas; en
+// los dos casos desplazada con ScrollBy. Una línea indica lo que no se ve:
+// arriba con "scroll" y abajo con "truncate".
+func (l *Layout) clip(name, content string, limit int, overflow string, muted lipgloss.Style) string {
+	lines := strings.Split(content, "\n")
+	l.clipped[name] = limit > 0 && len(lines) > limit
+	if !l.clipped[name] {
+		return content
+	}
+	if limit == 1 {
+		return lines[0]
+	}
+	visible := limit - 1
+	hidden := len(lines) - visible
+
+	offset := min(l.offsets[name], hidden)
+	l.offsets[name] = offset
+	start := offset
+	if overflow == OverflowScroll {
+		start = hidden - offset
+	}
+	window := lines[start : start+visible : start+visible]
+	above, below := start, hidden-start
+
+	var marker string
+	if overflow != OverflowScroll && above == 0 {
+		marker = muted.Render(fmt.Sprintf("… %d líneas más", below))
+	} else {
+		var marks []string
+		if above > 0 {
+			marks = append(marks, fmt.Sprintf("↑ %d", above))
 		}
+		if below > 0 {
+			marks = append(marks, fmt.Sprintf("↓ %d", below))
+		}
+		marker = muted.Render(strings.Join(marks, " · ") + " líneas más")
+	}
 
-	    position := b.Position()
-	    if position == "left" || position == "right" {
-	         if position == "left" {
-	            leftColumnViews = append(leftColumnViews, renderedBlock)
-	        } else {
-	            rightColumnViews = append(rightColumnViews, renderedBlock)
-	        }
-	    } else {
-	         processPendingColumns()
-	         finalLayout = append(finalLayout, renderedBlock)
-	    }
+	if overflow == OverflowScroll {
+		return strings.Join(append([]string{marker}, window...), "\n")
 	}
+	return strings.Join(append(window, marker), "\n")
+}
+
+// innerWidth es el ancho que queda para el contenido en una celda de
+// 'width' celdas con el marco 'border'.
+func innerWidth(width int, border lipgloss.Style) int {
+	return max(width-border.GetHorizontalFrameSize(), 1)
+}
 
-	processPendingColumns()
-	return lipgloss.JoinVertical(lipgloss.Left, finalLayout...)
-}
\ No newline at end of file
+// chromeLines son las líneas que el título y el pie añaden fuera del marco
+// cuando no hay borde donde meterlos.
+func chromeLines(border lipgloss.Style, status block.Status) int {
+	lines := 0
+	if (status.Title != "" || status.Err != nil) && !border.GetBorderTop() {
+		lines++
+	}
+	if status.Footer && !border.GetBorderBottom() {
+		lines++
+	}
+	return lines
+}
+
+// fitWidth recorta o rellena una línea hasta 'width' celdas.
+func fitWidth(line string, width int) string {
+	w := lipgloss.Width(line)
+	if w > width {
+		return ansi.Truncate(line, width, "")
+	}
+	return line + strings.Repeat(" ", width-w)
+}
+
+// padHeight añade líneas vacías hasta 'height'.
+func padHeight(s string, height int) string {
+	if missing := height - lipgloss.Height(s); missing > 0 {
+		s += strings.Repeat("\n", missing)
+	}
+	return s
+}
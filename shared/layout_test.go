@@ -0,0 +1,271 @@
+// shared/layout_test.go
+package shared
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/gas/fancy-welcome/config"
+	"github.com/gas/fancy-welcome/shared/block"
+	"github.com/gas/fancy-welcome/themes"
+)
+
+// testBlock es un bloque mínimo: un nombre, una posición y un texto fijo.
+type testBlock struct {
+	name     string
+	position string
+	view     string
+}
+
+func (b *testBlock) Init(map[string]interface{}, config.GeneralConfig, *themes.StyleSheet) error {
+	return nil
+}
+func (b *testBlock) Update(tea.Msg) (block.Block, tea.Cmd) { return b, nil }
+func (b *testBlock) View() string                           { return b.view }
+func (b *testBlock) Name() string                           { return b.name }
+func (b *testBlock) Position() string                       { return b.position }
+func (b *testBlock) RendererName() string                   { return "" }
+
+// lines devuelve un texto de 'n' líneas.
+func lines(n int) string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = strings.Repeat("x", i%3+1)
+	}
+	return strings.Join(out, "\n")
+}
+
+func TestColumnWidths(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []Track
+		width   int
+		want    []int
+	}{
+		{"fijas y fracciones", []Track{{Fixed: 30}, {Fraction: 2}, {Fraction: 1}}, 120, []int{30, 60, 30}},
+		{"resto del redondeo a la última fracción", []Track{{Fraction: 1}, {Fraction: 1}, {Fraction: 1}}, 100, []int{33, 33, 34}},
+		{"fracción entre fijas", []Track{{Fraction: 1}, {Fixed: 10}, {Fraction: 1}, {Fixed: 10}}, 25, []int{2, 10, 3, 10}},
+		{"más estrecho que las fijas", []Track{{Fixed: 30}, {Fixed: 20}, {Fraction: 1}}, 40, []int{30, 20, 0}},
+		{"solo fijas", []Track{{Fixed: 10}, {Fixed: 5}}, 100, []int{10, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnWidths(tt.columns, tt.width); !slices.Equal(got, tt.want) {
+				t.Errorf("columnWidths(%v, %d) = %v, se esperaba %v", tt.columns, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlace(t *testing.T) {
+	type spec struct {
+		position  string
+		placement Placement
+	}
+	tests := []struct {
+		name    string
+		columns int
+		blocks  []spec
+		want    [][2]int // Fila y columna de cada bloque
+	}{
+		{
+			name:    "siguiente hueco libre",
+			columns: 2,
+			blocks:  []spec{{"", Placement{Span: 1}}, {"", Placement{Span: 1}}, {"", Placement{Span: 1}}},
+			want:    [][2]int{{0, 0}, {0, 1}, {1, 0}},
+		},
+		{
+			name:    "column explícita",
+			columns: 2,
+			blocks:  []spec{{"", Placement{Column: 2}}, {"", Placement{Column: 1}}, {"", Placement{Column: 2}}},
+			want:    [][2]int{{0, 1}, {0, 0}, {1, 1}},
+		},
+		{
+			name:    "left y right",
+			columns: 3,
+			blocks:  []spec{{"right", Placement{}}, {"left", Placement{}}, {"left", Placement{}}},
+			want:    [][2]int{{0, 2}, {0, 0}, {1, 0}},
+		},
+		{
+			name:    "span que no cabe salta de fila",
+			columns: 3,
+			blocks:  []spec{{"", Placement{Span: 2}}, {"", Placement{Span: 2}}, {"", Placement{Span: 1}}},
+			want:    [][2]int{{0, 0}, {1, 0}, {1, 2}},
+		},
+		{
+			name:    "row_span ocupa las filas de debajo",
+			columns: 2,
+			blocks:  []spec{{"", Placement{Span: 1, RowSpan: 2}}, {"", Placement{Span: 1}}, {"", Placement{Span: 1}}},
+			want:    [][2]int{{0, 0}, {0, 1}, {1, 1}},
+		},
+		{
+			name:    "ancho completo empieza sección",
+			columns: 2,
+			blocks:  []spec{{"left", Placement{}}, {"", Placement{}}, {"right", Placement{}}},
+			want:    [][2]int{{0, 0}, {1, 0}, {2, 1}},
+		},
+		{
+			name:    "column fuera de la rejilla va a la última",
+			columns: 2,
+			blocks:  []spec{{"", Placement{Column: 5}}},
+			want:    [][2]int{{0, 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Layout{placements: make(map[string]Placement)}
+			var blocks []block.Block
+			for i, s := range tt.blocks {
+				name := string(rune('a' + i))
+				l.placements[name] = s.placement
+				blocks = append(blocks, &testBlock{name: name, position: s.position})
+			}
+			var got [][2]int
+			for _, c := range l.place(blocks, tt.columns) {
+				got = append(got, [2]int{c.row, c.col})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("place = %v, se esperaba %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClip(t *testing.T) {
+	content := "1\n2\n3\n4\n5\n6"
+	tests := []struct {
+		name     string
+		overflow string
+		limit    int
+		scroll   []int // Llamadas a ScrollBy antes del último clip
+		want     string
+	}{
+		{"sin límite", OverflowTruncate, 0, nil, content},
+		{"cabe", OverflowTruncate, 6, nil, content},
+		{"una sola línea", OverflowTruncate, 1, nil, "1"},
+		{"truncate", OverflowTruncate, 3, nil, "1\n2\n… 4 líneas más"},
+		{"truncate desplazado", OverflowTruncate, 3, []int{2}, "3\n4\n↑ 2 · ↓ 2 líneas más"},
+		{"truncate hasta el final", OverflowTruncate, 3, []int{10}, "5\n6\n↑ 4 líneas más"},
+		{"truncate vuelve al principio", OverflowTruncate, 3, []int{2, -5}, "1\n2\n… 4 líneas más"},
+		{"scroll sigue el final", OverflowScroll, 3, nil, "↑ 4 líneas más\n5\n6"},
+		{"scroll hacia el final no pasa del final", OverflowScroll, 3, []int{3}, "↑ 4 líneas más\n5\n6"},
+		{"scroll desplazado", OverflowScroll, 3, []int{-1}, "↑ 3 · ↓ 1 líneas más\n4\n5"},
+		{"scroll hasta el principio", OverflowScroll, 3, []int{-10}, "↓ 4 líneas más\n1\n2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Layout{
+				placements: map[string]Placement{"a": {Overflow: tt.overflow}},
+				offsets:    make(map[string]int),
+				clipped:    make(map[string]bool),
+			}
+			muted := lipgloss.NewStyle()
+			got := l.clip("a", content, tt.limit, tt.overflow, muted)
+			for _, delta := range tt.scroll {
+				if !l.ScrollBy("a", delta) {
+					t.Fatalf("ScrollBy(%d) = false en un bloque recortado", delta)
+				}
+				got = l.clip("a", content, tt.limit, tt.overflow, muted)
+			}
+			if got != tt.want {
+				t.Errorf("clip = %q, se esperaba %q", got, tt.want)
+			}
+		})
+	}
+
+	l := &Layout{offsets: make(map[string]int), clipped: make(map[string]bool)}
+	l.clip("a", content, 10, OverflowScroll, lipgloss.NewStyle())
+	if l.ScrollBy("a", 1) {
+		t.Error("ScrollBy = true en un bloque al que le cabe todo")
+	}
+}
+
+func TestLayoutAt(t *testing.T) {
+	cfg := &config.Config{Breakpoints: []config.BreakpointConfig{
+		{Below: 100, Layout: "single"},
+		{Below: 60, MinPriority: "normal"},
+		{Below: 80, MinPriority: "high"},
+		{Below: 50, Layout: "three"},
+		{Below: 0, Layout: "sidebar"}, // Se ignora
+	}}
+	l := NewLayout(cfg)
+
+	tests := []struct {
+		width           int
+		wantColumns     int
+		wantMinPriority int
+	}{
+		{120, 2, 0},
+		{100, 2, 0},
+		{99, 1, 0},
+		{79, 1, priorities["high"]},
+		{59, 1, priorities["high"]}, // "normal" no rebaja la de un breakpoint más ancho
+		{49, 3, priorities["high"]},
+	}
+	for _, tt := range tests {
+		columns, minPriority := l.at(tt.width)
+		if len(columns) != tt.wantColumns || minPriority != tt.wantMinPriority {
+			t.Errorf("at(%d) = %d columnas y prioridad %d, se esperaban %d y %d",
+				tt.width, len(columns), minPriority, tt.wantColumns, tt.wantMinPriority)
+		}
+	}
+
+	low := &testBlock{name: "low"}
+	l.placements["low"] = Placement{Priority: priorities["low"]}
+	if !l.Visible(99, low) || l.Visible(79, low) {
+		t.Error("un bloque de prioridad low debería verse con 99 columnas y ocultarse con 79")
+	}
+}
+
+func TestRenderRowHeights(t *testing.T) {
+	// 'a' ocupa las dos filas de la primera columna; 'b' y 'c' van una
+	// encima de otra en la segunda, que empieza en la celda 20. Con marco,
+	// cada bloque mide su contenido más dos líneas.
+	tests := []struct {
+		name     string
+		a, b, c  int   // Líneas de cada bloque
+		wantRows []int // Alto de cada fila
+	}{
+		{"row_span más alto que sus filas agranda la última", 10, 1, 1, []int{3, 9}},
+		{"row_span más bajo se estira", 2, 3, 3, []int{5, 5}},
+		{"row_span justo", 6, 2, 2, []int{4, 4}},
+	}
+	border := lipgloss.NewStyle().Border(lipgloss.NormalBorder())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLayout(&config.Config{})
+			l.placements["a"] = Placement{Column: 1, RowSpan: 2}
+			l.placements["b"] = Placement{Column: 2}
+			l.placements["c"] = Placement{Column: 2}
+			blocks := []block.Block{
+				&testBlock{name: "a", view: lines(tt.a)},
+				&testBlock{name: "b", view: lines(tt.b)},
+				&testBlock{name: "c", view: lines(tt.c)},
+			}
+
+			out := strings.Split(ansi.Strip(l.Render(40, blocks, -1, border, border)), "\n")
+			if total := tt.wantRows[0] + tt.wantRows[1]; len(out) != total {
+				t.Fatalf("Render da %d líneas, se esperaban %d", len(out), total)
+			}
+			// Las esquinas de arriba de la segunda columna marcan dónde
+			// empieza cada fila.
+			var tops []int
+			for y, line := range out {
+				if r := []rune(line); len(r) > 20 && r[20] == '┌' {
+					tops = append(tops, y)
+				}
+			}
+			if want := []int{0, tt.wantRows[0]}; !slices.Equal(tops, want) {
+				t.Errorf("las filas empiezan en %v, se esperaba %v", tops, want)
+			}
+			if last := []rune(out[len(out)-1]); len(last) == 0 || last[0] != '└' {
+				t.Errorf("el bloque con row_span no llega a la última línea: %q", out[len(out)-1])
+			}
+		})
+	}
+}
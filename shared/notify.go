@@ -0,0 +1,182 @@
+// shared/notify.go
+package shared
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/charmbracelet/bubbletea"
+    "github.com/charmbracelet/lipgloss"
+    "github.com/charmbracelet/x/ansi"
+
+    "github.com/gas/fancy-welcome/logging"
+    "github.com/gas/fancy-welcome/shared/block"
+    "github.com/gas/fancy-welcome/themes"
+)
+
+const (
+    toastDuration    = 4 * time.Second // Lo que dura un toast en pantalla
+    maxNotifications = 200             // Las que guarda el panel de mensajes
+
+    // MessageLogHeight es el alto del panel de mensajes, con su marco.
+    MessageLogHeight = 8
+)
+
+// NotificationExpiredMsg quita el toast 'id', si no lo ha sustituido otro.
+type NotificationExpiredMsg struct{ id int }
+
+// Notifications lleva los avisos de la TUI: el toast que se ve un momento
+// abajo y el historial que enseña el panel de mensajes.
+type Notifications struct {
+    history []block.NotificationMsg
+    toast   *block.NotificationMsg
+    id      int
+    failing map[string]string // Último error visto de cada bloque (Watch)
+}
+
+// NewNotifications crea un historial vacío.
+func NewNotifications() *Notifications {
+    return &Notifications{failing: make(map[string]string)}
+}
+
+// Record guarda una notificación en el historial (y en el log) sin
+// enseñarla como toast.
+func (n *Notifications) Record(msg block.NotificationMsg) {
+    if msg.Time.IsZero() {
+        msg.Time = time.Now()
+    }
+    logging.Log.Printf("[%s] %s: %s", msg.Source, msg.Severity, msg.Text)
+    n.history = append(n.history, msg)
+    if len(n.history) > maxNotifications {
+        n.history = n.history[len(n.history)-maxNotifications:]
+    }
+}
+
+// Add guarda una notificación y la enseña como toast hasta que caduque o
+// llegue otra.
+func (n *Notifications) Add(msg block.NotificationMsg) tea.Cmd {
+    n.Record(msg)
+    latest := n.history[len(n.history)-1]
+    n.toast = &latest
+    n.id++
+    id := n.id
+    return tea.Tick(toastDuration, func(time.Time) tea.Msg { return NotificationExpiredMsg{id: id} })
+}
+
+// Expire quita el toast si 'msg' es el suyo.
+func (n *Notifications) Expire(msg NotificationExpiredMsg) {
+    if msg.id == n.id {
+        n.toast = nil
+    }
+}
+
+// RecordSetup guarda en el historial los problemas de la config y los
+// errores al iniciar los bloques, y devuelve un toast que los resume (nil
+// si no hay ninguno).
+func (n *Notifications) RecordSetup(result *SetupResult) tea.Cmd {
+    severity := block.SeverityWarning
+    for _, p := range result.Problems {
+        s := block.SeverityWarning
+        if !p.Warning {
+            s, severity = block.SeverityError, block.SeverityError
+        }
+        n.Record(block.NewNotification(s, "", "%s", p.String()))
+    }
+    for _, notice := range result.Notices {
+        if notice.Severity == block.SeverityError {
+            severity = block.SeverityError
+        }
+        n.Record(notice)
+    }
+
+    total := len(result.Problems) + len(result.Notices)
+    if total == 0 {
+        return nil
+    }
+    return block.Notify(severity, "", "%d problemas al cargar la configuración (ver mensajes)", total)
+}
+
+// Watch avisa cuando un bloque pasa a tener un error (o cambia de error) y
+// apunta cuando se recupera. Se llama tras repartir mensajes a los bloques.
+func (n *Notifications) Watch(blocks []block.Block) tea.Cmd {
+    var cmds []tea.Cmd
+    for _, b := range blocks {
+        reporter, ok := b.(block.Reporter)
+        if !ok {
+            continue
+        }
+        err := reporter.Status().Err
+        last, failing := n.failing[b.Name()]
+        switch {
+        case err != nil && (!failing || last != err.Error()):
+            n.failing[b.Name()] = err.Error()
+            cmds = append(cmds, n.Add(block.NewNotification(block.SeverityError, b.Name(), "%v", err)))
+        case err == nil && failing:
+            delete(n.failing, b.Name())
+            n.Record(block.NewNotification(block.SeveritySuccess, b.Name(), "vuelve a funcionar"))
+        }
+    }
+    return tea.Batch(cmds...)
+}
+
+// severityStyle es el color del tema para cada gravedad, con su marca.
+func severityStyle(styles *themes.StyleSheet, severity block.Severity) (lipgloss.Style, string) {
+    switch severity {
+    case block.SeveritySuccess:
+        return styles.Success, "✓"
+    case block.SeverityWarning:
+        return styles.Warning, "!"
+    case block.SeverityError:
+        return styles.Error, "✗"
+    default:
+        return styles.Primary, "i"
+    }
+}
+
+// noteText es el texto de una notificación: "bloque: texto".
+func noteText(msg block.NotificationMsg) string {
+    if msg.Source == "" {
+        return msg.Text
+    }
+    return msg.Source + ": " + msg.Text
+}
+
+// Overlay pinta el toast, si hay, sobre la última línea de 'view', a la derecha.
+func (n *Notifications) Overlay(view string, width int, styles *themes.StyleSheet) string {
+    if n.toast == nil || width <= 0 {
+        return view
+    }
+    style, mark := severityStyle(styles, n.toast.Severity)
+    label := style.Reverse(true).Bold(true).Render(" " + mark + " " + noteText(*n.toast) + " ")
+    label = ansi.Truncate(label, width, "…")
+
+    lines := strings.Split(view, "\n")
+    last := len(lines) - 1
+    room := width - lipgloss.Width(label)
+    lines[last] = fitWidth(ansi.Truncate(lines[last], room, ""), room) + label
+    return strings.Join(lines, "\n")
+}
+
+// RenderLog compone el panel de mensajes: las últimas notificaciones, la
+// más reciente abajo, en un marco de width x height.
+func (n *Notifications) RenderLog(width, height int, styles *themes.StyleSheet) string {
+    border := styles.Border
+    inner := innerWidth(width, border)
+    rows := max(height-border.GetVerticalFrameSize(), 1)
+
+    var lines []string
+    for _, msg := range n.history[max(len(n.history)-rows, 0):] {
+        style, mark := severityStyle(styles, msg.Severity)
+        line := styles.Muted.Render(msg.Time.Format("15:04:05")) + " " + style.Render(mark+" "+noteText(msg))
+        lines = append(lines, ansi.Truncate(line, inner, "…"))
+    }
+    if len(lines) == 0 {
+        lines = append(lines, styles.Muted.Render("Sin mensajes"))
+    }
+    content := padHeight(strings.Join(lines, "\n"), rows)
+
+    border = border.Width(max(width-border.GetHorizontalBorderSize(), 1))
+    status := block.Status{Chrome: block.Chrome{Title: fmt.Sprintf("Mensajes (%d)", len(n.history))}}
+    return frameBlock(content, border, styles, status)
+}
@@ -0,0 +1,152 @@
+// shared/pages.go
+package shared
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+
+    "github.com/charmbracelet/lipgloss"
+    "github.com/charmbracelet/x/ansi"
+
+    "github.com/gas/fancy-welcome/config"
+    "github.com/gas/fancy-welcome/shared/block"
+    "github.com/gas/fancy-welcome/themes"
+)
+
+// DefaultHiddenPageRefresh es cuántas veces más despacio se refrescan los
+// bloques de las páginas que no se ven, si [general] no dice otra cosa.
+const DefaultHiddenPageRefresh = 4
+
+// Page es una pestaña del dashboard: sus bloques, con su propio layout.
+type Page struct {
+    Name   string
+    Title  string
+    Blocks []string
+    Layout *Layout
+}
+
+// NewPages construye las páginas de [pages], en el orden del archivo.
+// Devuelve nil si no hay ninguna: el dashboard es una sola página con
+// todos los bloques.
+func NewPages(cfg *config.Config) []*Page {
+    names := make([]string, 0, len(cfg.Pages))
+    for name := range cfg.Pages {
+        names = append(names, name)
+    }
+    sort.Slice(names, func(i, j int) bool {
+        li, lj := cfg.Line("pages."+names[i], ""), cfg.Line("pages."+names[j], "")
+        if li != lj {
+            return li < lj
+        }
+        return names[i] < names[j]
+    })
+
+    var pages []*Page
+    for _, name := range names {
+        pageConfig := cfg.Pages[name]
+        title := pageConfig.Title
+        if title == "" {
+            title = name
+        }
+        layoutName := pageConfig.Layout
+        if _, err := layoutColumns(cfg, layoutName); err != nil {
+            layoutName = cfg.General.Layout
+        }
+        pages = append(pages, &Page{
+            Name:   name,
+            Title:  title,
+            Blocks: pageConfig.Blocks,
+            Layout: newLayout(cfg, layoutName, append([]string{}, pageConfig.Blocks...)),
+        })
+    }
+    return pages
+}
+
+// HiddenPageRefresh devuelve cuántas veces más despacio se refrescan las
+// páginas que no se ven.
+func HiddenPageRefresh(cfg *config.Config) float64 {
+    if cfg.General.HiddenPageRefresh < 1 {
+        return DefaultHiddenPageRefresh
+    }
+    return cfg.General.HiddenPageRefresh
+}
+
+// SlowBlocks devuelve los bloques que solo están en páginas distintas de
+// 'current', los que se refrescan más despacio mientras no se ven.
+func SlowBlocks(pages []*Page, current int) []string {
+    visible := make(map[string]bool)
+    if current >= 0 && current < len(pages) {
+        for _, name := range pages[current].Blocks {
+            visible[name] = true
+        }
+    }
+    var slow []string
+    seen := make(map[string]bool)
+    for i, page := range pages {
+        if i == current {
+            continue
+        }
+        for _, name := range page.Blocks {
+            if !visible[name] && !seen[name] {
+                seen[name] = true
+                slow = append(slow, name)
+            }
+        }
+    }
+    return slow
+}
+
+// tabLabels son los textos de las pestañas: el número (la tecla que elige
+// la página, hasta 9), el título y una marca si algún bloque tiene errores.
+func tabLabels(pages []*Page, blocks []block.Block) []string {
+    failing := make(map[string]bool)
+    for _, b := range blocks {
+        if reporter, ok := b.(block.Reporter); ok && reporter.Status().Err != nil {
+            failing[b.Name()] = true
+        }
+    }
+
+    labels := make([]string, len(pages))
+    for i, page := range pages {
+        label := " " + page.Title + " "
+        if i < 9 {
+            label = fmt.Sprintf(" %d %s ", i+1, page.Title)
+        }
+        for _, name := range page.Blocks {
+            if failing[name] {
+                label += "✗ "
+                break
+            }
+        }
+        labels[i] = label
+    }
+    return labels
+}
+
+// RenderTabBar compone la barra de pestañas con la página 'active' resaltada.
+func RenderTabBar(width int, styles *themes.StyleSheet, pages []*Page, active int, blocks []block.Block) string {
+    tabs := tabLabels(pages, blocks)
+    for i, label := range tabs {
+        if i == active {
+            tabs[i] = styles.Title.Render(label)
+        } else {
+            tabs[i] = styles.Muted.Render(label)
+        }
+    }
+    return ansi.Truncate(strings.Join(tabs, " "), width, "…")
+}
+
+// TabAt devuelve la página que está en la columna 'x' de la barra de
+// pestañas, o -1 si no hay ninguna.
+func TabAt(pages []*Page, blocks []block.Block, x int) int {
+    start := 0
+    for i, label := range tabLabels(pages, blocks) {
+        w := lipgloss.Width(label)
+        if x >= start && x < start+w {
+            return i
+        }
+        start += w + 1
+    }
+    return -1
+}
@@ -0,0 +1,261 @@
+// shared/reload.go
+package shared
+
+import (
+    "path/filepath"
+    "reflect"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/charmbracelet/bubbletea"
+    "github.com/fsnotify/fsnotify"
+
+    "github.com/gas/fancy-welcome/config"
+    "github.com/gas/fancy-welcome/logging"
+    "github.com/gas/fancy-welcome/shared/block"
+    "github.com/gas/fancy-welcome/themes"
+)
+
+// reloadDelay agrupa las ráfagas de eventos de un guardado (los editores
+// suelen escribir, renombrar y cambiar permisos casi a la vez).
+const reloadDelay = 250 * time.Millisecond
+
+// ConfigChangedMsg avisa de que ha cambiado en disco la config o un tema.
+type ConfigChangedMsg struct {
+    Path string
+}
+
+// Reloader recarga en caliente la configuración y el tema de una TUI:
+// vigila los archivos y, cuando cambian, vuelve a montar los bloques
+// conservando los que no han cambiado. También lleva el tema elegido con
+// la tecla de cambio de tema, que se mantiene entre recargas.
+type Reloader struct {
+    themeName string // Tema elegido en caliente ("" = el de la config)
+
+    mu      sync.Mutex
+    program block.Sender
+    watcher *fsnotify.Watcher
+}
+
+// NewReloader crea un Reloader sin vigilar nada todavía.
+func NewReloader() *Reloader {
+    return &Reloader{}
+}
+
+// SetProgram guarda el programa al que se envían los ConfigChangedMsg y
+// que reciben los bloques en streaming que se crean al recargar.
+func (r *Reloader) SetProgram(p block.Sender) {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.program = p
+}
+
+// Watch empieza a vigilar el archivo de configuración, los directorios de
+// configuración (por si se crea uno) y los de temas. Los directorios que
+// no existen se ignoran.
+func (r *Reloader) Watch(cfg *config.Config) error {
+    watcher, err := fsnotify.NewWatcher()
+    if err != nil {
+        return err
+    }
+
+    // Vigilamos directorios y no archivos: los editores y WriteFileAtomic
+    // sustituyen el archivo por otro, y eso rompe la vigilancia de un archivo.
+    dirs := append(config.Dirs(), themes.Dirs()...)
+    if cfg.Path != "" {
+        dirs = append(dirs, filepath.Dir(cfg.Path))
+    }
+    seen := make(map[string]bool)
+    for _, dir := range dirs {
+        if seen[dir] {
+            continue
+        }
+        seen[dir] = true
+        if err := watcher.Add(dir); err == nil {
+            logging.Log.Printf("Watching %s for changes", dir)
+        }
+    }
+
+    r.watcher = watcher
+    go r.watch(watcher)
+    return nil
+}
+
+// watch convierte los eventos de fsnotify en ConfigChangedMsg.
+func (r *Reloader) watch(watcher *fsnotify.Watcher) {
+    var timer *time.Timer
+    for {
+        select {
+        case event, ok := <-watcher.Events:
+            if !ok {
+                return
+            }
+            // Solo los .toml; los temporales de un guardado atómico no cuentan.
+            if !strings.HasSuffix(event.Name, ".toml") || event.Op == fsnotify.Chmod {
+                continue
+            }
+            path := event.Name
+            if timer != nil {
+                timer.Stop()
+            }
+            timer = time.AfterFunc(reloadDelay, func() {
+                r.mu.Lock()
+                p := r.program
+                r.mu.Unlock()
+                if p != nil {
+                    p.Send(ConfigChangedMsg{Path: path})
+                }
+            })
+        case err, ok := <-watcher.Errors:
+            if !ok {
+                return
+            }
+            logging.Log.Printf("Error watching config: %v", err)
+        }
+    }
+}
+
+// Close deja de vigilar los archivos.
+func (r *Reloader) Close() error {
+    if r.watcher == nil {
+        return nil
+    }
+    return r.watcher.Close()
+}
+
+// Reload vuelve a leer la config y el tema. Los bloques de 'current' cuya
+// config no ha cambiado se conservan (con su estado y sus streams) y solo
+// cambian de estilos; el resto se cierran y se crean de nuevo. Devuelve el
+// nuevo SetupResult y el comando que arranca los bloques nuevos.
+func (r *Reloader) Reload(previous *SetupResult, current []block.Block) (*SetupResult, tea.Cmd, error) {
+    result, err := setup("", previous.Mode, r.themeName, previous.Config, current...)
+    if err != nil {
+        return nil, nil, err
+    }
+    // Los bloques desplazados siguen donde estaban.
+    result.Layout.offsets = previous.Layout.offsets
+    for _, page := range result.Pages {
+        for _, old := range previous.Pages {
+            if old.Name == page.Name {
+                page.Layout.offsets = old.Layout.offsets
+            }
+        }
+    }
+
+    active := make(map[block.Block]bool, len(result.ActiveBlocks))
+    for _, b := range result.ActiveBlocks {
+        active[b] = true
+    }
+    var dropped []block.Block
+    running := make(map[block.Block]bool, len(current))
+    for _, b := range current {
+        running[b] = true
+        if !active[b] {
+            dropped = append(dropped, b)
+        }
+    }
+    CloseBlocks(dropped)
+
+    r.mu.Lock()
+    p := r.program
+    r.mu.Unlock()
+
+    var cmds []tea.Cmd
+    for _, b := range result.ActiveBlocks {
+        if running[b] {
+            continue
+        }
+        if streamer, ok := b.(block.Streamer); ok && p != nil {
+            streamer.SetProgram(p)
+        }
+        tick := block.NewBlockTickMsg(b.Name())
+        cmds = append(cmds, func() tea.Msg { return tick })
+    }
+    logging.Log.Printf("Config reloaded: %d blocks, %d started, %d closed",
+        len(result.ActiveBlocks), len(cmds), len(dropped))
+    return result, tea.Batch(cmds...), nil
+}
+
+// CycleTheme pasa al siguiente tema disponible (en el orden de
+// themes.List) y lo aplica a los bloques sin reinicializarlos.
+// Devuelve el nombre del tema nuevo.
+func (r *Reloader) CycleTheme(result *SetupResult, blocks []block.Block) (string, error) {
+    infos, err := themes.List()
+    if err != nil {
+        return "", err
+    }
+
+    current := r.themeName
+    if current == "" {
+        current = result.Config.Theme.SelectedTheme
+    }
+    next := infos[0].ID
+    for i, info := range infos {
+        if info.ID == current {
+            next = infos[(i+1)%len(infos)].ID
+            break
+        }
+    }
+
+    theme, err := themes.LoadTheme(next)
+    if err != nil {
+        return "", err
+    }
+    r.themeName = next
+    result.Theme = theme
+    for _, b := range blocks {
+        restyle(b, result.Config, theme)
+    }
+    return next, nil
+}
+
+// reusable devuelve el bloque en marcha llamado 'name' si su config no ha
+// cambiado respecto a 'previous', o nil si hay que crearlo de nuevo.
+func reusable(running []block.Block, previous, cfg *config.Config, name string) block.Block {
+    if previous == nil {
+        return nil
+    }
+    oldConfig, _ := previous.Blocks[name].(map[string]interface{})
+    newConfig, _ := cfg.Blocks[name].(map[string]interface{})
+    if !sameBlockConfig(oldConfig, newConfig) {
+        return nil
+    }
+    for _, b := range running {
+        if b.Name() == name {
+            newConfig["name"] = name
+            return b
+        }
+    }
+    return nil
+}
+
+// sameBlockConfig compara dos configs de bloque ignorando 'name', que
+// Setup añade al inicializar.
+func sameBlockConfig(a, b map[string]interface{}) bool {
+    if a == nil || b == nil {
+        return false
+    }
+    strip := func(m map[string]interface{}) map[string]interface{} {
+        out := make(map[string]interface{}, len(m))
+        for k, v := range m {
+            if k != "name" {
+                out[k] = v
+            }
+        }
+        return out
+    }
+    return reflect.DeepEqual(strip(a), strip(b))
+}
+
+// restyle aplica el tema (y la tabla 'style' del bloque, si la tiene) a un
+// bloque en marcha.
+func restyle(b block.Block, cfg *config.Config, theme *themes.Theme) {
+    restyler, ok := b.(block.Restyler)
+    if !ok {
+        return
+    }
+    blockConfig, _ := cfg.Blocks[b.Name()].(map[string]interface{})
+    blockStyle, _ := themes.ParseBlockStyle(blockConfig["style"])
+    restyler.SetStyles(theme.BlockStyleSheet(blockStyle))
+}
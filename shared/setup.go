@@ -2,14 +2,14 @@
 package shared
 
 import (
-    "fmt" // Necesitamos fmt para getCacheFilePath
+    "fmt"
     "os"
-    "path/filepath"
     "log" // Necesitamos log para el Printf de error
 
     // Todos los paquetes necesarios para la inicialización
+    "github.com/gas/fancy-welcome/cache"
     "github.com/gas/fancy-welcome/config"
-    //"github.com/gas/fancy-welcome/logging"
+    "github.com/gas/fancy-welcome/logging"
     "github.com/gas/fancy-welcome/shared/block"
     "github.com/gas/fancy-welcome/themes"
     "github.com/gas/fancy-welcome/blocks/shell_command"
@@ -18,76 +18,168 @@ import (
     "github.com/gas/fancy-welcome/blocks/filter"
 )
 
+// Modos de ejecución. Un bloque con 'run_mode' distinto de "all" solo se
+// inicializa en el modo que indique.
+const (
+    RunModeTUI = "tui"
+    RunModeTTY = "tty"
+)
+
 // SetupResult agrupa todo lo que la inicialización produce.
 type SetupResult struct {
     Config       *config.Config
     Theme        *themes.Theme
     ActiveBlocks []block.Block
     BlockFactory map[string]func() block.Block
+    Problems     []Problem // Errores y avisos de la validación de la config
+    Layout       *Layout   // Rejilla en la que se colocan los bloques
+    Pages        []*Page   // Pestañas de [pages]; nil si no hay
+    Keys         *KeyMap   // Teclas de la TUI ([keys])
+    Notices      []block.NotificationMsg // Errores al iniciar los bloques, para la TUI
+    Mode         string    // RunModeTUI o RunModeTTY
+}
+
+// NewBlockFactory devuelve los tipos de bloque disponibles.
+func NewBlockFactory() map[string]func() block.Block {
+    return map[string]func() block.Block{
+        "ShellCommand": shell_command.New,
+        "SystemInfo":   system_info.New,
+        "WordCounter":  word_counter.New,
+        "Filter":       filter.New,
+    }
 }
 
-// getCacheFilePath es una función helper que ahora vive aquí.
-// La hacemos privada (minúscula) porque solo se usa dentro de este paquete.
-func getCacheFilePath(blockName string) string {
-    homeDir, _ := os.UserHomeDir()
-    return filepath.Join(homeDir, ".cache", "fancy-welcome", fmt.Sprintf("%s.json", blockName))
+// Setup realiza toda la carga de configuración e inicialización de bloques
+// para el modo de ejecución indicado (RunModeTUI o RunModeTTY).
+func Setup(refreshTarget string, mode string) (*SetupResult, error) {
+    return setup(refreshTarget, mode, "", nil)
 }
 
-// Setup realiza toda la carga de configuración e inicialización de bloques.
-func Setup(refreshTarget string) (*SetupResult, error) {
+// setup hace el trabajo de Setup y de Reloader.Reload. 'themeName', si no
+// está vacío, sustituye al tema de la config. Con 'reuse' (bloques en
+// marcha por nombre) y 'previous' (la config con la que se crearon), los
+// bloques cuya config no ha cambiado se conservan tal cual, con su estado.
+func setup(refreshTarget, mode, themeName string, previous *config.Config, reuse ...block.Block) (*SetupResult, error) {
     cfg, err := config.LoadConfig()
     if err != nil { return nil, err }
 
-    theme, err := themes.LoadTheme(cfg.Theme.SelectedTheme)
+    // El tema de la config se adapta al fondo de la terminal; uno elegido
+    // en caliente se respeta tal cual.
+    var theme *themes.Theme
+    if themeName == "" {
+        theme, err = themes.LoadVariant(cfg.Theme.SelectedTheme, themes.DarkBackground(cfg.Theme.Background))
+    } else {
+        theme, err = themes.LoadTheme(themeName)
+    }
     if err != nil { return nil, err }
 
     // Lógica de caché (tomada de tu main.go original)
-    homeDir, _ := os.UserHomeDir()
-    cacheDir := filepath.Join(homeDir, ".cache", "fancy-welcome")
-    if err := os.MkdirAll(cacheDir, 0755); err != nil {
+    if err := os.MkdirAll(cache.Dir(), 0755); err != nil {
         return nil, err
     }
 
-    // Fábrica de bloques (tomada de tu main.go original)
-    blockFactory := map[string]func() block.Block{
-        "ShellCommand": shell_command.New,
-        "SystemInfo":   system_info.New,
-        "WordCounter":  word_counter.New,
-        "Filter":       filter.New,
+    blockFactory := NewBlockFactory()
+
+    // Validamos todo antes de inicializar nada: un bloque mal configurado
+    // se salta (con su error en el log) en lugar de romper en su View.
+    problems := Validate(cfg, blockFactory)
+    for _, p := range problems {
+        logging.Log.Print(p.String())
     }
+    invalid := invalidBlocks(problems)
 
     var activeBlocks []block.Block
+    var notices []block.NotificationMsg
     for _, blockName := range cfg.General.EnabledBlocksOrder {
         // ... (Aquí va toda la lógica del bucle de tu runTuiMode )
         // para refrescar la caché, comprobar el run_mode, usar la factory, etc.
         
         if refreshTarget == "all" || refreshTarget == blockName {
-            cacheFile := getCacheFilePath(blockName)
-            _ = os.Remove(cacheFile) // Ignoramos el error si el archivo no existe
+            if err := cache.Remove(blockName); err != nil {
+                log.Printf("Error borrando la caché de '%s': %v", blockName, err)
+                notices = append(notices, block.NewNotification(block.SeverityWarning, blockName, "no se pudo borrar la caché: %v", err))
+            }
         }
 
         blockConfig, _ := cfg.Blocks[blockName].(map[string]interface{})    
         runMode, _ := blockConfig["run_mode"].(string)
         if runMode == "" { runMode = "all" }
-        // Si el bloque está configurado para ejecutarse solo en modo 'tty', saltamos.
-        if runMode == "tty" { continue }
-
-        blockType, _ := blockConfig["type"].(string)
-        if factory, ok := blockFactory[blockType]; ok {
-            b := factory()
-            blockConfig["name"] = blockName
-            if err := b.Init(blockConfig, cfg.General, theme); err != nil {
-                log.Printf("Error inicializando bloque '%s': %v", blockName, err)
-                continue
-            }
+        // Si el bloque está configurado para ejecutarse solo en otro modo, saltamos.
+        if runMode != "all" && runMode != mode { continue }
+
+        if blockConfig == nil || invalid[blockName] { continue }
+
+        if b := reusable(reuse, previous, cfg, blockName); b != nil {
+            restyle(b, cfg, theme)
+            activeBlocks = append(activeBlocks, b)
+            continue
+        }
+
+        b, err := initBlock(blockFactory, cfg, theme, blockName)
+        if err != nil {
+            log.Printf("Error inicializando bloque '%s': %v", blockName, err)
+            notices = append(notices, block.NewNotification(block.SeverityError, blockName, "no se pudo iniciar: %v", err))
+            continue
+        }
+        if b != nil {
             activeBlocks = append(activeBlocks, b)
         }
     }
 
+    keys := NewKeyMap(cfg)
+    BindKeys(activeBlocks, keys)
+
     return &SetupResult{
         Config:       cfg,
         Theme:        theme,
         ActiveBlocks: activeBlocks,
         BlockFactory: blockFactory,
+        Problems:     problems,
+        Layout:       NewLayout(cfg),
+        Pages:        NewPages(cfg),
+        Keys:         keys,
+        Notices:      notices,
+        Mode:         mode,
     }, nil
-}
\ No newline at end of file
+}
+
+// initBlock crea e inicializa el bloque 'blockName' definido en la config.
+// Devuelve nil sin error si su tipo no está registrado.
+func initBlock(blockFactory map[string]func() block.Block, cfg *config.Config, theme *themes.Theme, blockName string) (block.Block, error) {
+    blockConfig, _ := cfg.Blocks[blockName].(map[string]interface{})
+    blockType, _ := blockConfig["type"].(string)
+    factory, ok := blockFactory[blockType]
+    if !ok { return nil, nil }
+
+    // Los errores de 'style' ya los ha avisado Validate; aplicamos lo válido.
+    blockStyle, _ := themes.ParseBlockStyle(blockConfig["style"])
+
+    b := factory()
+    blockConfig["name"] = blockName
+    if err := b.Init(blockConfig, cfg.General, theme.BlockStyleSheet(blockStyle)); err != nil {
+        return nil, err
+    }
+    return b, nil
+}
+
+// NewBlock inicializa un bloque de la config aunque no esté en
+// 'enabled_blocks_order' (por ejemplo, la fuente de 'filter --simple').
+func (r *SetupResult) NewBlock(blockName string) (block.Block, error) {
+    if _, ok := r.Config.Blocks[blockName].(map[string]interface{}); !ok {
+        return nil, fmt.Errorf("el bloque '%s' no está definido en la configuración", blockName)
+    }
+    for _, p := range r.Problems {
+        if p.Block == blockName && !p.Warning {
+            return nil, fmt.Errorf("el bloque '%s' tiene errores de configuración: %s", blockName, p)
+        }
+    }
+    b, err := initBlock(r.BlockFactory, r.Config, r.Theme, blockName)
+    if err != nil {
+        return nil, fmt.Errorf("error inicializando bloque '%s': %w", blockName, err)
+    }
+    if b == nil {
+        return nil, fmt.Errorf("el bloque '%s' tiene un tipo desconocido", blockName)
+    }
+    BindKeys([]block.Block{b}, r.Keys)
+    return b, nil
+}
@@ -0,0 +1,273 @@
+// shared/validate.go
+package shared
+
+import (
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+
+    "github.com/gas/fancy-welcome/config"
+    "github.com/gas/fancy-welcome/shared/block"
+    "github.com/gas/fancy-welcome/themes"
+)
+
+// Problem es un error (o aviso) de la configuración, con su posición en
+// el archivo para poder corregirlo sin buscar.
+type Problem struct {
+    File    string
+    Line    int    // 0 si no se conoce
+    Block   string // "" si es de [general]
+    Key     string
+    Message string
+    Warning bool
+}
+
+func (p Problem) String() string {
+    var builder strings.Builder
+    builder.WriteString(p.File)
+    if p.Line > 0 {
+        fmt.Fprintf(&builder, ":%d", p.Line)
+    }
+    if p.Warning {
+        builder.WriteString(": aviso: ")
+    } else {
+        builder.WriteString(": error: ")
+    }
+    if p.Block != "" {
+        fmt.Fprintf(&builder, "[blocks.%s] ", p.Block)
+    }
+    if p.Key != "" {
+        fmt.Fprintf(&builder, "'%s': ", p.Key)
+    }
+    builder.WriteString(p.Message)
+    return builder.String()
+}
+
+// HasErrors indica si hay algún problema que no sea un aviso.
+func HasErrors(problems []Problem) bool {
+    for _, p := range problems {
+        if !p.Warning {
+            return true
+        }
+    }
+    return false
+}
+
+// ReportProblems escribe los problemas, uno por línea.
+func ReportProblems(w io.Writer, problems []Problem) {
+    for _, p := range problems {
+        fmt.Fprintln(w, p.String())
+    }
+}
+
+// Validate comprueba toda la configuración de una vez: el orden de
+// [general], el tipo de cada bloque y sus claves según el schema de su
+// tipo. Rellena los valores por defecto, así que hay que llamarla antes
+// de inicializar los bloques.
+func Validate(cfg *config.Config, blockFactory map[string]func() block.Block) []Problem {
+    var problems []Problem
+    add := func(blockName, key, message string, warning bool) {
+        table := "general"
+        if blockName != "" {
+            table = "blocks." + blockName
+        }
+        problems = append(problems, Problem{
+            File:    cfg.Source(),
+            Line:    cfg.Line(table, key),
+            Block:   blockName,
+            Key:     key,
+            Message: message,
+            Warning: warning,
+        })
+    }
+
+    names := make([]string, 0, len(cfg.Blocks))
+    for name := range cfg.Blocks {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    for _, name := range cfg.General.EnabledBlocksOrder {
+        if _, ok := cfg.Blocks[name]; !ok {
+            add("", "enabled_blocks_order", fmt.Sprintf("el bloque %q no está definido%s", name, block.Suggestion(name, names)), false)
+        }
+    }
+
+    // Un fondo desconocido no impide arrancar: se trata como "auto".
+    switch cfg.Theme.Background {
+    case "", themes.BackgroundAuto, themes.BackgroundDark, themes.BackgroundLight:
+    default:
+        problems = append(problems, Problem{
+            File:    cfg.Source(),
+            Line:    cfg.Line("theme", "background"),
+            Key:     "theme.background",
+            Message: fmt.Sprintf("fondo %q no válido (válidos: auto, dark, light)", cfg.Theme.Background),
+            Warning: true,
+        })
+    }
+
+    // Los layouts mal escritos tampoco: se usa el integrado.
+    for _, name := range sortedKeys(cfg.Layouts) {
+        if _, err := layoutColumns(cfg, name); err != nil {
+            problems = append(problems, Problem{
+                File:    cfg.Source(),
+                Line:    cfg.Line("layouts."+name, "columns"),
+                Key:     "layouts." + name,
+                Message: err.Error(),
+                Warning: true,
+            })
+        }
+    }
+    if _, defined := cfg.Layouts[cfg.General.Layout]; cfg.General.Layout != "" && !defined {
+        if _, err := layoutColumns(cfg, cfg.General.Layout); err != nil {
+            add("", "layout", err.Error()+"; se usa \""+DefaultLayout+"\"", true)
+        }
+    }
+
+    for i, rule := range cfg.Breakpoints {
+        table := fmt.Sprintf("breakpoints[%d]", i)
+        warn := func(key, message string) {
+            problems = append(problems, Problem{
+                File:    cfg.Source(),
+                Line:    cfg.Line(table, key),
+                Key:     table + "." + key,
+                Message: message + "; se ignora",
+                Warning: true,
+            })
+        }
+        if rule.Below <= 0 {
+            warn("below", "falta el ancho (en columnas) por debajo del que se aplica")
+        }
+        if rule.Layout != "" {
+            if _, err := layoutColumns(cfg, rule.Layout); err != nil {
+                warn("layout", err.Error())
+            }
+        }
+        if _, ok := priorities[rule.MinPriority]; rule.MinPriority != "" && !ok {
+            warn("min_priority", fmt.Sprintf("prioridad %q no válida (válidas: low, normal, high)", rule.MinPriority))
+        }
+    }
+
+    // Las páginas tampoco: lo que falte simplemente no se ve.
+    enabled := make(map[string]bool, len(cfg.General.EnabledBlocksOrder))
+    for _, name := range cfg.General.EnabledBlocksOrder {
+        enabled[name] = true
+    }
+    for _, page := range sortedKeys(cfg.Pages) {
+        warn := func(key, message string) {
+            problems = append(problems, Problem{
+                File:    cfg.Source(),
+                Line:    cfg.Line("pages."+page, key),
+                Key:     "pages." + page + "." + key,
+                Message: message,
+                Warning: true,
+            })
+        }
+        pageConfig := cfg.Pages[page]
+        if len(pageConfig.Blocks) == 0 {
+            warn("blocks", "la página no tiene bloques")
+        }
+        for _, name := range pageConfig.Blocks {
+            if _, ok := cfg.Blocks[name]; !ok {
+                warn("blocks", fmt.Sprintf("el bloque %q no está definido%s", name, block.Suggestion(name, names)))
+            } else if !enabled[name] {
+                warn("blocks", fmt.Sprintf("el bloque %q no está en 'enabled_blocks_order' y no se muestra", name))
+            }
+        }
+        if pageConfig.Layout != "" {
+            if _, err := layoutColumns(cfg, pageConfig.Layout); err != nil {
+                warn("layout", err.Error()+"; se usa el de [general]")
+            }
+        }
+    }
+    if refresh := cfg.General.HiddenPageRefresh; refresh != 0 && refresh < 1 {
+        add("", "hidden_page_refresh", fmt.Sprintf("debe ser 1 o más (es %v); se usa %d", refresh, DefaultHiddenPageRefresh), true)
+    }
+
+    // Ni las teclas: una mal puesta o repetida se queda sin acción.
+    _, keyProblems := resolveKeys(cfg)
+    problems = append(problems, keyProblems...)
+
+    types := make([]string, 0, len(blockFactory))
+    for t := range blockFactory {
+        types = append(types, t)
+    }
+    sort.Strings(types)
+
+    for _, name := range names {
+        blockConfig, ok := cfg.Blocks[name].(map[string]interface{})
+        if !ok {
+            add(name, "", "un bloque debe ser una tabla [blocks."+name+"]", false)
+            continue
+        }
+
+        blockType, _ := blockConfig["type"].(string)
+        factory, ok := blockFactory[blockType]
+        if !ok {
+            message := "falta la clave obligatoria (tipo de bloque)"
+            if _, present := blockConfig["type"]; present {
+                message = fmt.Sprintf("tipo de bloque %q desconocido%s (válidos: %s)",
+                    blockConfig["type"], block.Suggestion(blockType, types), strings.Join(types, ", "))
+            }
+            add(name, "type", message, false)
+            continue
+        }
+
+        if configurable, ok := factory().(block.Configurable); ok {
+            for _, issue := range configurable.ConfigSchema().Validate(blockConfig, names) {
+                add(name, issue.Key, issue.Message, issue.Warning)
+            }
+        }
+
+        // La tabla 'style' la entiende el paquete de temas. Son avisos: lo
+        // que esté mal se ignora y el bloque funciona con el resto.
+        if _, isTable := blockConfig["style"].(map[string]interface{}); isTable {
+            _, styleErrs := themes.ParseBlockStyle(blockConfig["style"])
+            for _, styleErr := range styleErrs {
+                // "colors.primary" está en la subtabla [blocks.<name>.style.colors].
+                table, key := "blocks."+name+".style", styleErr.Key
+                if i := strings.LastIndex(key, "."); i >= 0 {
+                    table, key = table+"."+key[:i], key[i+1:]
+                }
+                line := cfg.Line(table, key)
+                if line == 0 {
+                    line = cfg.Line("blocks."+name, "style")
+                }
+                problems = append(problems, Problem{
+                    File:    cfg.Source(),
+                    Line:    line,
+                    Block:   name,
+                    Key:     "style." + styleErr.Key,
+                    Message: styleErr.Message,
+                    Warning: true,
+                })
+            }
+        }
+    }
+
+    // En el orden del archivo, que es como se van a corregir.
+    sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+    return problems
+}
+
+// invalidBlocks devuelve los bloques con algún error (no avisos).
+func invalidBlocks(problems []Problem) map[string]bool {
+    invalid := make(map[string]bool)
+    for _, p := range problems {
+        if !p.Warning && p.Block != "" {
+            invalid[p.Block] = true
+        }
+    }
+    return invalid
+}
+
+// sortedKeys devuelve las claves de 'm' en orden.
+func sortedKeys[V any](m map[string]V) []string {
+    keys := make([]string, 0, len(m))
+    for key := range m {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
@@ -0,0 +1,101 @@
+// themes/color.go
+package themes
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+)
+
+// Modos de color de '--color', como los de grep o ls.
+const (
+	ColorAuto   = "auto"   // Según la terminal, NO_COLOR y CLICOLOR_FORCE
+	ColorNever  = "never"  // Sin ningún escape de color ni de estilo
+	ColorAlways = "always" // Con color aunque la salida no sea una terminal
+)
+
+// Fondos de '[theme] background'.
+const (
+	BackgroundAuto  = "auto"
+	BackgroundDark  = "dark"
+	BackgroundLight = "light"
+)
+
+// SetColorMode fija el perfil de color con el que lipgloss renderiza todo.
+// Los colores del tema son hexadecimales; lipgloss los reduce al perfil
+// (256 o 16 colores) o los quita (monocromo) al renderizar.
+func SetColorMode(mode string) error {
+	switch mode {
+	case "", ColorAuto:
+		// lipgloss ya detecta el perfil y respeta NO_COLOR y CLICOLOR_FORCE.
+	case ColorNever:
+		lipgloss.SetColorProfile(termenv.Ascii)
+	case ColorAlways:
+		// Sin terminal, TERM y COLORTERM dicen qué entiende quien lea la
+		// salida (un log de CI, less -R...); como mínimo, 16 colores.
+		profile := termenv.NewOutput(os.Stdout, termenv.WithTTY(true)).ColorProfile()
+		if profile == termenv.Ascii {
+			profile = termenv.ANSI
+		}
+		lipgloss.SetColorProfile(profile)
+	default:
+		return fmt.Errorf("modo de color %q no válido (válidos: %s, %s, %s)", mode, ColorAuto, ColorNever, ColorAlways)
+	}
+	return nil
+}
+
+// Monochrome indica si la salida va sin colores, así que el foco y los
+// estados no pueden distinguirse solo por el color.
+func Monochrome() bool {
+	return lipgloss.ColorProfile() == termenv.Ascii
+}
+
+// DarkBackground resuelve '[theme] background'. En "auto" pregunta a la
+// terminal por su color de fondo; si la salida no es una terminal o va sin
+// colores no hay nada que preguntar y se asume oscuro.
+func DarkBackground(background string) bool {
+	switch background {
+	case BackgroundDark:
+		return true
+	case BackgroundLight:
+		return false
+	}
+	if Monochrome() || !term.IsTerminal(os.Stdout.Fd()) {
+		return true
+	}
+	return lipgloss.HasDarkBackground()
+}
+
+// LoadVariant carga el tema 'themeName' o, si es de la otra variante
+// (clara u oscura) que el fondo de la terminal, su pareja: la que indican
+// sus claves 'light' / 'dark' o, si no las tiene, la del mismo nombre con
+// el sufijo cambiado ("-dark" <-> "-light"). Sin pareja, el tema tal cual.
+func LoadVariant(themeName string, dark bool) (*Theme, error) {
+	theme, err := LoadTheme(themeName)
+	if err != nil {
+		return nil, err
+	}
+
+	want, counterpart := BackgroundDark, theme.Dark
+	if !dark {
+		want, counterpart = BackgroundLight, theme.Light
+	}
+	if theme.Variant == "" || theme.Variant == want {
+		return theme, nil
+	}
+
+	if counterpart == "" {
+		if !strings.HasSuffix(themeName, "-"+theme.Variant) {
+			return theme, nil
+		}
+		counterpart = strings.TrimSuffix(themeName, theme.Variant) + want
+	}
+	if _, _, err := Read(counterpart); err != nil {
+		return theme, nil
+	}
+	return LoadTheme(counterpart)
+}
@@ -0,0 +1,309 @@
+// themes/styles.go
+package themes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// StyleSheet son los estilos de lipgloss derivados de la paleta del tema.
+// Los bloques y renderers usan estos estilos en lugar de construir colores
+// a mano, así un cambio de tema llega a toda la UI.
+type StyleSheet struct {
+	Base      lipgloss.Style // Texto normal del bloque (background + text)
+	Primary   lipgloss.Style
+	Secondary lipgloss.Style
+	Muted     lipgloss.Style // Información secundaria (marcas de caché, pies)
+	Error     lipgloss.Style
+	Success   lipgloss.Style
+	Warning   lipgloss.Style
+
+	Title       lipgloss.Style // Barra de título de un bloque
+	TitleAlign  lipgloss.Position
+	TableHeader lipgloss.Style
+
+	GaugeFull      lipgloss.Style
+	GaugeEmpty     lipgloss.Style
+	GaugeFullChar  string
+	GaugeEmptyChar string
+
+	Border        lipgloss.Style // Marco de un bloque
+	FocusedBorder lipgloss.Style // Marco del bloque con el foco
+
+	Indicators map[string]IndicatorStyle // Indicadores de carga del tema
+}
+
+// StyleSheet construye los estilos del tema.
+func (t *Theme) StyleSheet() *StyleSheet {
+	c := t.Colors
+	color := func(value string) lipgloss.TerminalColor {
+		if value == "" {
+			return lipgloss.NoColor{}
+		}
+		return lipgloss.Color(value)
+	}
+
+	base := lipgloss.NewStyle().
+		Background(color(c.Background)).
+		Foreground(color(c.Text))
+
+	s := &StyleSheet{
+		Base:      base,
+		Primary:   base.Foreground(color(c.Primary)),
+		Secondary: base.Foreground(color(c.Secondary)),
+		Muted:     base.Faint(true),
+		Error:     base.Foreground(color(c.Error)),
+		Success:   base.Foreground(color(c.Success)),
+		Warning:   base.Foreground(color(c.Warning)),
+
+		Title: lipgloss.NewStyle().
+			Background(color(c.TitleBackground)).
+			Foreground(color(c.TitleForeground)).
+			Bold(true),
+		TableHeader: base.Bold(true).Foreground(color(c.Primary)),
+
+		GaugeFull:      lipgloss.NewStyle().Foreground(color(c.GaugeFull)),
+		GaugeEmpty:     lipgloss.NewStyle().Foreground(color(c.GaugeEmpty)),
+		GaugeFullChar:  "█",
+		GaugeEmptyChar: "░",
+
+		Border: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(color(c.Border)),
+		FocusedBorder: lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(color(c.Primary)),
+
+		Indicators: t.Indicators,
+	}
+
+	// Sin colores, el bloque con el foco se distingue por el grosor del marco.
+	if Monochrome() {
+		s.FocusedBorder = s.FocusedBorder.BorderStyle(monochromeFocus(""))
+	}
+
+	// El indicador 'bar' del tema puede cambiar los caracteres y el color de las barras.
+	if bar, ok := t.Indicators["bar"]; ok {
+		if bar.FullChar != "" {
+			s.GaugeFullChar = bar.FullChar
+		}
+		if bar.EmptyChar != "" {
+			s.GaugeEmptyChar = bar.EmptyChar
+		}
+		if bar.Color != "" {
+			s.GaugeFull = s.GaugeFull.Foreground(lipgloss.Color(bar.Color))
+		}
+	}
+	return s
+}
+
+// BlockStyle son los ajustes de estilo de un bloque, la tabla
+// [blocks.<nombre>.style] de la configuración:
+//
+//	border      = "rounded"  # rounded, normal, thick, double, hidden o none
+//	padding     = [0, 1]     # 1, 2 o 4 valores, como en CSS
+//	title_bold  = true
+//	title_align = "center"   # left, center o right
+//
+//	[blocks.<nombre>.style.colors]  # cualquier rol de la paleta del tema
+//	border = "#BF616A"
+type BlockStyle struct {
+	Border     string
+	Padding    []int
+	TitleBold  *bool
+	TitleAlign string
+	Colors     map[string]string
+}
+
+// StyleError es un error en una clave de la tabla 'style' de un bloque.
+type StyleError struct {
+	Key     string // Relativa a la tabla 'style' ("border", "colors.primary"...)
+	Message string
+}
+
+func (e StyleError) Error() string { return e.Key + ": " + e.Message }
+
+var borders = map[string]lipgloss.Border{
+	"rounded": lipgloss.RoundedBorder(),
+	"normal":  lipgloss.NormalBorder(),
+	"thick":   lipgloss.ThickBorder(),
+	"double":  lipgloss.DoubleBorder(),
+	"hidden":  lipgloss.HiddenBorder(),
+}
+
+var titleAligns = map[string]lipgloss.Position{
+	"left":   lipgloss.Left,
+	"center": lipgloss.Center,
+	"right":  lipgloss.Right,
+}
+
+// ParseBlockStyle lee la tabla 'style' de la config de un bloque tal como
+// la decodifica go-toml. Devuelve todos los errores encontrados; las claves
+// con error se ignoran y el resto se aplica igualmente.
+func ParseBlockStyle(raw interface{}) (BlockStyle, []StyleError) {
+	var style BlockStyle
+	if raw == nil {
+		return style, nil
+	}
+	table, ok := raw.(map[string]interface{})
+	if !ok {
+		return style, []StyleError{{Message: "'style' debe ser una tabla"}}
+	}
+
+	var errs []StyleError
+	fail := func(key, format string, args ...interface{}) {
+		errs = append(errs, StyleError{Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for key, value := range table {
+		switch key {
+		case "border":
+			name, _ := value.(string)
+			if _, ok := borders[name]; !ok && name != "none" {
+				fail(key, "borde %v no válido (válidos: %s, none)", value, strings.Join(sortedKeys(borders), ", "))
+				continue
+			}
+			style.Border = name
+		case "padding":
+			padding, ok := intList(value)
+			if !ok || (len(padding) != 1 && len(padding) != 2 && len(padding) != 4) {
+				fail(key, "se esperaba un entero o una lista de 1, 2 o 4 enteros")
+				continue
+			}
+			style.Padding = padding
+		case "title_bold":
+			bold, ok := value.(bool)
+			if !ok {
+				fail(key, "se esperaba true o false")
+				continue
+			}
+			style.TitleBold = &bold
+		case "title_align":
+			align, _ := value.(string)
+			if _, ok := titleAligns[align]; !ok {
+				fail(key, "alineación %v no válida (válidas: left, center, right)", value)
+				continue
+			}
+			style.TitleAlign = align
+		case "colors":
+			colors, ok := value.(map[string]interface{})
+			if !ok {
+				fail(key, "'colors' debe ser una tabla de roles del tema")
+				continue
+			}
+			style.Colors = make(map[string]string, len(colors))
+			var probe ThemeColors
+			for role, v := range colors {
+				str, ok := v.(string)
+				if !ok || !probe.Set(role, str) {
+					fail("colors."+role, "se esperaba un color para un rol del tema (%s)", strings.Join(roleKeys(), ", "))
+					continue
+				}
+				style.Colors[role] = str
+			}
+		default:
+			fail(key, "clave desconocida (válidas: border, padding, title_bold, title_align, colors)")
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Key < errs[j].Key })
+	return style, errs
+}
+
+// BlockStyleSheet combina el tema con los ajustes de un bloque y devuelve
+// el estilo final que recibe el bloque en Init.
+func (t *Theme) BlockStyleSheet(style BlockStyle) *StyleSheet {
+	if len(style.Colors) == 0 && style.Border == "" && style.Padding == nil &&
+		style.TitleBold == nil && style.TitleAlign == "" {
+		return t.StyleSheet()
+	}
+
+	local := *t
+	if len(style.Colors) > 0 {
+		// Los roles que el tema no define se vuelven a derivar, así
+		// 'success' o 'gauge_full' siguen a un 'primary' propio del bloque.
+		colors := t.declared
+		if colors == (ThemeColors{}) {
+			colors = t.Colors // Tema hecho a mano, sin LoadTheme
+		}
+		for role, value := range style.Colors {
+			colors.Set(role, value)
+		}
+		colors.fillDefaults()
+		local.Colors = colors
+	}
+	s := local.StyleSheet()
+
+	switch style.Border {
+	case "":
+	case "none":
+		// Sin marco, ni siquiera al tener el foco.
+		s.Border = lipgloss.NewStyle()
+		s.FocusedBorder = lipgloss.NewStyle()
+	default:
+		s.Border = s.Border.BorderStyle(borders[style.Border])
+		focused := borders[style.Border]
+		if Monochrome() {
+			focused = monochromeFocus(style.Border)
+		}
+		s.FocusedBorder = s.FocusedBorder.BorderStyle(focused)
+	}
+	if style.Padding != nil {
+		s.Border = s.Border.Padding(style.Padding...)
+		s.FocusedBorder = s.FocusedBorder.Padding(style.Padding...)
+	}
+	if style.TitleBold != nil {
+		s.Title = s.Title.Bold(*style.TitleBold)
+	}
+	if style.TitleAlign != "" {
+		s.TitleAlign = titleAligns[style.TitleAlign]
+	}
+	return s
+}
+
+// monochromeFocus es el marco del bloque con el foco cuando no hay colores:
+// uno distinto del normal ('border'), que es grueso salvo si el normal ya lo es.
+func monochromeFocus(border string) lipgloss.Border {
+	if border == "thick" {
+		return lipgloss.DoubleBorder()
+	}
+	return lipgloss.ThickBorder()
+}
+
+// intList acepta un entero o una lista de enteros de TOML.
+func intList(value interface{}) ([]int, bool) {
+	switch v := value.(type) {
+	case int64:
+		return []int{int(v)}, v >= 0
+	case []interface{}:
+		list := make([]int, 0, len(v))
+		for _, item := range v {
+			n, ok := item.(int64)
+			if !ok || n < 0 {
+				return nil, false
+			}
+			list = append(list, int(n))
+		}
+		return list, true
+	}
+	return nil, false
+}
+
+func roleKeys() []string {
+	var keys []string
+	for _, role := range (ThemeColors{}).Roles() {
+		keys = append(keys, role.Key)
+	}
+	return keys
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -2,17 +2,42 @@
 package themes
 
 import (
+	"embed"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	//"github.com/charmbracelet/lipgloss"
+	"github.com/gas/fancy-welcome/config"
 	"github.com/pelletier/go-toml/v2"
 )
 
+// DefaultTheme es el tema que se usa si la config no elige ninguno.
+const DefaultTheme = "default"
+
+// Los temas integrados van dentro del binario, así funciona desde cualquier
+// directorio. Un archivo con el mismo nombre en un directorio de temas los
+// sustituye.
+//
+//go:embed *.toml
+var builtin embed.FS
+
+// builtinSource es el origen que se muestra para los temas integrados.
+const builtinSource = "(integrado)"
+
+// IndicatorStyle es un indicador del tema: los fotogramas de un spinner
+// o los caracteres de una barra ('bar').
 type IndicatorStyle struct {
-	Frames []string `toml:"frames"`
+	Frames    []string `toml:"frames"`
+	FullChar  string   `toml:"full_char"`
+	EmptyChar string   `toml:"empty_char"`
+	Color     string   `toml:"color"`
 }
 
+// ThemeColors es la paleta semántica del tema. Los roles están
+// documentados en themes/default.toml.
 type ThemeColors struct {
 	Primary    string `toml:"primary"`
 	Secondary  string `toml:"secondary"`
@@ -20,29 +45,269 @@ type ThemeColors struct {
 	Text       string `toml:"text"`
 	Border     string `toml:"border"`
 	Error      string `toml:"error"`
+	Success    string `toml:"success"`
+	Warning    string `toml:"warning"`
+
+	TitleBackground string `toml:"title_background"`
+	TitleForeground string `toml:"title_foreground"`
+	GaugeFull       string `toml:"gauge_full"`
+	GaugeEmpty      string `toml:"gauge_empty"`
+}
+
+// fillDefaults da valor a los roles que el tema no define a partir de
+// otros parecidos, para que los temas antiguos (con solo seis colores)
+// sigan viéndose bien.
+func (c *ThemeColors) fillDefaults() {
+	fallback := func(role *string, value string) {
+		if *role == "" {
+			*role = value
+		}
+	}
+	fallback(&c.Success, c.Primary)
+	fallback(&c.Warning, c.Error)
+	fallback(&c.TitleForeground, c.Text)
+	fallback(&c.GaugeFull, c.Success)
+	fallback(&c.GaugeEmpty, c.Border)
+}
+
+// Set cambia el color del rol 'key' (la clave del TOML). Devuelve false
+// si no existe ese rol.
+func (c *ThemeColors) Set(key, value string) bool {
+	roles := map[string]*string{
+		"background":       &c.Background,
+		"text":             &c.Text,
+		"primary":          &c.Primary,
+		"secondary":        &c.Secondary,
+		"error":            &c.Error,
+		"success":          &c.Success,
+		"warning":          &c.Warning,
+		"border":           &c.Border,
+		"title_background": &c.TitleBackground,
+		"title_foreground": &c.TitleForeground,
+		"gauge_full":       &c.GaugeFull,
+		"gauge_empty":      &c.GaugeEmpty,
+	}
+	role, ok := roles[key]
+	if ok {
+		*role = value
+	}
+	return ok
+}
+
+// ColorRole es un color del tema junto a su clave en el TOML.
+type ColorRole struct {
+	Key   string
+	Value string
+}
+
+// Roles devuelve los colores en el orden en que se documentan.
+func (c ThemeColors) Roles() []ColorRole {
+	return []ColorRole{
+		{"background", c.Background},
+		{"text", c.Text},
+		{"primary", c.Primary},
+		{"secondary", c.Secondary},
+		{"error", c.Error},
+		{"success", c.Success},
+		{"warning", c.Warning},
+		{"border", c.Border},
+		{"title_background", c.TitleBackground},
+		{"title_foreground", c.TitleForeground},
+		{"gauge_full", c.GaugeFull},
+		{"gauge_empty", c.GaugeEmpty},
+	}
 }
 
 type Theme struct {
-	Name   string      `toml:"name"`
+	Name    string `toml:"name"`
+	Extends string `toml:"extends"` // Tema base: este solo redefine lo que cambia
+	Variant string `toml:"variant"` // "dark" o "light": para qué fondo está pensado
+	Light   string `toml:"light"`   // Pareja clara de un tema oscuro (ver LoadVariant)
+	Dark    string `toml:"dark"`    // Pareja oscura de un tema claro
 	Colors ThemeColors `toml:"colors"`
 	Indicators map[string]IndicatorStyle `toml:"indicators"`
+
+	// declared es la paleta tal como la define el tema, antes de
+	// fillDefaults: los colores de un bloque se aplican sobre ella para que
+	// los roles derivados los sigan.
+	declared ThemeColors
+}
+
+// Info describe un tema disponible: su nombre (el del archivo, el que se
+// pone en 'selected_theme'), su título y de dónde sale.
+type Info struct {
+	ID     string
+	Name   string
+	Source string
+}
+
+// Dirs devuelve los directorios donde se buscan temas, en orden de
+// prioridad: los del usuario, /etc/fancy-welcome/themes y
+// /usr/share/fancy-welcome/themes. Los integrados van después de todos.
+func Dirs() []string {
+	var dirs []string
+	for _, dir := range config.Dirs() {
+		dirs = append(dirs, filepath.Join(dir, "themes"))
+	}
+	return append(dirs, filepath.Join("/usr/share", "fancy-welcome", "themes"))
+}
+
+// Read devuelve el TOML del tema y su origen (ruta o "(integrado)").
+// 'themeName' puede ser también la ruta de un archivo .toml.
+func Read(themeName string) ([]byte, string, error) {
+	if themeName == "" {
+		themeName = DefaultTheme
+	}
+
+	if strings.ContainsRune(themeName, os.PathSeparator) || strings.HasSuffix(themeName, ".toml") {
+		data, err := os.ReadFile(themeName)
+		if err != nil {
+			return nil, "", fmt.Errorf("no se pudo leer el archivo de tema %s: %w", themeName, err)
+		}
+		return data, themeName, nil
+	}
+
+	for _, dir := range Dirs() {
+		filePath := filepath.Join(dir, themeName+".toml")
+		data, err := os.ReadFile(filePath)
+		if err == nil {
+			return data, filePath, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, "", fmt.Errorf("no se pudo leer el archivo de tema %s: %w", filePath, err)
+		}
+	}
+
+	data, err := builtin.ReadFile(themeName + ".toml")
+	if err != nil {
+		return nil, "", fmt.Errorf("tema '%s' no encontrado (buscado en %s y en los integrados)", themeName, strings.Join(Dirs(), ", "))
+	}
+	return data, builtinSource, nil
 }
 
 func LoadTheme(themeName string) (*Theme, error) {
-    // Por ahora, asumimos que los temas están en el mismo directorio que el ejecutable.
-    // En el futuro, se podría buscar en ~/.config/fancy-welcome/themes/ también.
-	filePath := fmt.Sprintf("themes/%s.toml", themeName)
-	
-	data, err := os.ReadFile(filePath)
+	data, source, err := Read(themeName)
 	if err != nil {
-		return nil, fmt.Errorf("no se pudo leer el archivo de tema %s: %w", filePath, err)
+		return nil, err
 	}
 
 	var theme Theme
-	err = toml.Unmarshal(data, &theme)
-	if err != nil {
-		return nil, fmt.Errorf("no se pudo parsear el TOML del tema: %w", err)
+	if err := decodeTheme(&theme, themeName, data, source, nil); err != nil {
+		return nil, err
+	}
+
+	// La variante y sus parejas no se heredan: un tema que extiende
+	// "default" no es "default", y no debe cambiarse por "default-light".
+	var variant struct {
+		Variant string `toml:"variant"`
+		Light   string `toml:"light"`
+		Dark    string `toml:"dark"`
 	}
+	if err := toml.Unmarshal(data, &variant); err != nil {
+		return nil, fmt.Errorf("no se pudo parsear el TOML del tema %s: %w", source, err)
+	}
+	theme.Variant, theme.Light, theme.Dark = variant.Variant, variant.Light, variant.Dark
+	theme.declared = theme.Colors
+	theme.Colors.fillDefaults()
 
 	return &theme, nil
 }
+
+// decodeTheme decodifica un tema sobre 'theme' después de decodificar su
+// tema base ('extends'), así el hijo solo pisa las claves que define.
+// 'chain' son los temas ya visitados, para detectar ciclos.
+func decodeTheme(theme *Theme, themeName string, data []byte, source string, chain []string) error {
+	var header struct {
+		Extends string `toml:"extends"`
+	}
+	if err := toml.Unmarshal(data, &header); err != nil {
+		return fmt.Errorf("no se pudo parsear el TOML del tema %s: %w", source, err)
+	}
+
+	if parent := header.Extends; parent != "" {
+		chain = append(chain, themeName)
+		var parentData []byte
+		var parentSource string
+		var err error
+		if parent == themeName && source != builtinSource {
+			// Un tema de usuario que se extiende a sí mismo ajusta el integrado del mismo nombre.
+			parentData, err = builtin.ReadFile(parent + ".toml")
+			parentSource = builtinSource
+			if err != nil {
+				return fmt.Errorf("el tema %s extiende '%s', que no es un tema integrado", source, parent)
+			}
+		} else {
+			for _, visited := range chain {
+				if visited == parent {
+					return fmt.Errorf("herencia circular de temas: %s -> %s", strings.Join(chain, " -> "), parent)
+				}
+			}
+			parentData, parentSource, err = Read(parent)
+			if err != nil {
+				return fmt.Errorf("el tema %s extiende '%s': %w", source, parent, err)
+			}
+		}
+		if err := decodeTheme(theme, parent, parentData, parentSource, chain); err != nil {
+			return err
+		}
+	}
+
+	if err := toml.Unmarshal(data, theme); err != nil {
+		return fmt.Errorf("no se pudo parsear el TOML del tema %s: %w", source, err)
+	}
+	return nil
+}
+
+// List devuelve todos los temas disponibles, ordenados por nombre. Si un
+// tema está en varios sitios, cuenta el que ganaría LoadTheme.
+func List() ([]Info, error) {
+	found := make(map[string]Info)
+	add := func(fileName string, source string, data []byte) {
+		id := strings.TrimSuffix(fileName, ".toml")
+		if _, ok := found[id]; ok || fileName == id {
+			return
+		}
+		var theme Theme
+		if err := toml.Unmarshal(data, &theme); err != nil {
+			theme.Name = "(TOML inválido)"
+		}
+		found[id] = Info{ID: id, Name: theme.Name, Source: source}
+	}
+
+	for _, dir := range Dirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue // Lo normal es que la mayoría no existan.
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			filePath := filepath.Join(dir, entry.Name())
+			data, err := os.ReadFile(filePath)
+			if err != nil {
+				continue
+			}
+			add(entry.Name(), filePath, data)
+		}
+	}
+
+	entries, err := builtin.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		data, err := builtin.ReadFile(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		add(entry.Name(), builtinSource, data)
+	}
+
+	infos := make([]Info, 0, len(found))
+	for _, info := range found {
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ID < infos[j].ID })
+	return infos, nil
+}
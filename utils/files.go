@@ -0,0 +1,40 @@
+// utils/files.go
+package utils
+
+import (
+    "os"
+    "path/filepath"
+)
+
+// WriteFileAtomic escribe en un temporal del mismo directorio y lo renombra,
+// para no dejar nunca un archivo a medias. Conserva los permisos del archivo
+// si ya existía; si no, usa 'perm'.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+    dir := filepath.Dir(path)
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return err
+    }
+
+    if info, err := os.Stat(path); err == nil {
+        perm = info.Mode().Perm()
+    }
+
+    tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name()) // No-op si el rename ha ido bien.
+
+    if _, err := tmp.Write(data); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Chmod(perm); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), path)
+}